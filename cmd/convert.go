@@ -7,6 +7,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -22,8 +23,28 @@ var (
 	formatStr       string
 	outputFilePath  string
 	mergeToolChoice string
+	mergeModeChoice string
+	bomRefScheme    string
+	specVersionStr  string
+	deduplicate     bool
+	reproducible    bool
+	mergePolicySpec string
+	conflictsPath   string
 	ctfPath         string
 	componentName   string
+	eventsFilePath  string
+	showProgress    bool
+	labelAllow      []string
+	labelDeny       []string
+	supplierConfig  string
+	verifyKeys      []string
+	verifyCABundle  string
+	attach          bool
+	attachChildren  bool
+	attachVersion   string
+	publish         bool
+	publishRepo     string
+	publishInsecure bool
 )
 
 // convertCmd represents the convert command
@@ -73,61 +94,168 @@ Example:
 				parsedFormats = append(parsedFormats, converter.FormatSPDXJSON)
 			case "cyclonedx-yaml":
 				parsedFormats = append(parsedFormats, converter.FormatCycloneDXYAML)
+			case "cyclonedx-xml":
+				parsedFormats = append(parsedFormats, converter.FormatCycloneDXXML)
 			case "spdx-yaml":
 				parsedFormats = append(parsedFormats, converter.FormatSPDXYAML)
+			case "spdx-tag-value", "spdx-tv":
+				parsedFormats = append(parsedFormats, converter.FormatSPDXTagValue)
+			case "spdx3-json":
+				parsedFormats = append(parsedFormats, converter.FormatSPDX3JSON)
 			default:
-				return fmt.Errorf("unsupported SBOM format '%s'. Supported: cyclonedx-json, spdx-json, cyclonedx-yaml, spdx-yaml", f)
+				return fmt.Errorf("unsupported SBOM format '%s'. Supported: cyclonedx-json, spdx-json, cyclonedx-yaml, cyclonedx-xml, spdx-yaml, spdx-tag-value, spdx3-json", f)
 			}
 		}
 
+		// Merge mode
+		var mergeMode converter.CycloneDxMergeMode
+		switch strings.ToLower(strings.TrimSpace(mergeModeChoice)) {
+		case "hierarchical":
+			mergeMode = converter.MergeModeHierarchical
+		case "flat":
+			mergeMode = converter.MergeModeFlat
+		default:
+			return fmt.Errorf("unsupported merge mode '%s'. Supported: hierarchical, flat", mergeModeChoice)
+		}
+
+		// bom-ref scheme
+		var refScheme converter.BOMRefScheme
+		switch strings.ToLower(strings.TrimSpace(bomRefScheme)) {
+		case "namespaced":
+			refScheme = converter.BOMRefSchemeNamespaced
+		case "ocm":
+			refScheme = converter.BOMRefSchemeOCM
+		default:
+			return fmt.Errorf("unsupported bom-ref scheme '%s'. Supported: namespaced, ocm", bomRefScheme)
+		}
+
+		// CycloneDX spec version
+		specVersion, err := converter.ParseSpecVersion(specVersionStr)
+		if err != nil {
+			return fmt.Errorf("invalid --spec-version: %w", err)
+		}
+
 		// Converter
 		conv, err := converter.NewCLIConverter("", "", "", "", "")
 		if err != nil {
 			return fmt.Errorf("failed to initialize SBOM converter: %w", err)
 		}
+		conv.ToolVersion = readModuleVersion()
+		conv.MergeMode = mergeMode
+		conv.BOMRefScheme = refScheme
+		conv.SpecVersion = specVersion
+		conv.Deduplicate = deduplicate
+		conv.Reproducible = reproducible
+		if mergePolicySpec != "" {
+			policy, err := converter.ParseMergePolicy(mergePolicySpec)
+			if err != nil {
+				return fmt.Errorf("invalid --merge-policy: %w", err)
+			}
+			// Hierarchical merges keep every package in its image; only deduplication brings them together
+			if mergeMode == converter.MergeModeHierarchical && !deduplicate {
+				return fmt.Errorf("--merge-policy requires --dedup or --merge-mode flat")
+			}
+			conv.MergePolicy = &policy
+		}
+		if supplierConfig != "" {
+			conv.SupplierConfig, err = converter.LoadSupplierConfig(supplierConfig)
+			if err != nil {
+				return err
+			}
+		}
+		if len(verifyKeys) > 0 {
+			conv.SignatureVerifier, err = converter.NewSignatureVerifier(verifyKeys, verifyCABundle)
+			if err != nil {
+				return fmt.Errorf("invalid --verify-signature: %w", err)
+			}
+		} else if verifyCABundle != "" {
+			return fmt.Errorf("--verify-ca-bundle requires --verify-signature")
+		}
+		if !attach && (attachChildren || attachVersion != "") {
+			return fmt.Errorf("--attach-children and --attach-version require --attach")
+		}
+		if !publish && (publishRepo != "" || publishInsecure) {
+			return fmt.Errorf("--publish-repository and --publish-insecure require --publish")
+		}
+		if len(labelAllow) > 0 || len(labelDeny) > 0 {
+			conv.LabelFilter, err = converter.NewLabelFilter(labelAllow, labelDeny)
+			if err != nil {
+				return fmt.Errorf("invalid label filter: %w", err)
+			}
+		}
 		defer conv.CleanupTempDir() // Aufräumen der temporären Dateien
 
+		// Progress reporting
+		var reporters converter.MultiReporter
+		if showProgress {
+			reporters = append(reporters, converter.NewTerminalReporter(os.Stderr))
+		}
+		if eventsFilePath != "" {
+			eventsOut := os.Stdout
+			if eventsFilePath != "-" {
+				eventsOut, err = os.Create(eventsFilePath)
+				if err != nil {
+					return fmt.Errorf("failed to create events file %s: %w", eventsFilePath, err)
+				}
+				defer eventsOut.Close()
+			}
+			reporters = append(reporters, converter.NewNDJSONReporter(eventsOut))
+		}
+		if len(reporters) > 0 {
+			conv.Progress = reporters
+		}
+
 		log.Printf("Processing OCM component: %s from CTF: %s\n", componentName, ctfPath)
 		log.Printf("Target formats: %v\n", parsedFormats)
 		log.Printf("Output path: %s\n", outputFilePath)
 		log.Printf("ComponentSbomMerge tool: %s", mergeToolChoice)
+		log.Printf("Merge mode: %s", mergeMode)
+
+		// Scan and merge once, then render every requested format
+		sboms, err := conv.ConvertOCMToSBOMs(ctfPath, componentName, parsedFormats, mergeToolChoice)
+		if err != nil {
+			if errors.Is(err, converter.ErrMergeConflict) {
+				if reportErr := writeConflictReport(conv); reportErr != nil {
+					log.Printf("Warning: %v", reportErr)
+				}
+			}
+			return fmt.Errorf("error processing SBOM: %w", err)
+		}
 
-		// convert
 		for _, format := range parsedFormats {
 			currentOutputFilePath := outputFilePath
 			if len(parsedFormats) > 1 {
-				ext := ""
-				switch format {
-				case converter.FormatCycloneDXJSON:
-					ext = ".cdx.json"
-				case converter.FormatSPDXJSON:
-					ext = ".spdx.json"
-				case converter.FormatCycloneDXYAML:
-					ext = ".cdx.yaml"
-				case converter.FormatSPDXYAML:
-					ext = ".spdx.yaml"
-				}
-				currentOutputFilePath = fmt.Sprintf("%s%s", strings.TrimSuffix(outputFilePath, filepath.Ext(outputFilePath)), ext)
+				currentOutputFilePath = converter.OutputPathForFormat(outputFilePath, format)
 			}
 
-			log.Printf("Generating SBOM for format: %s to %s\n", format, currentOutputFilePath)
+			// Write result in a file
+			if err := os.WriteFile(currentOutputFilePath, sboms[format], 0644); err != nil {
+				return fmt.Errorf("failed to write SBOM to %s: %w", currentOutputFilePath, err)
+			}
+			log.Printf("Successfully generated %s SBOM to %s\n", format, currentOutputFilePath)
+		}
 
-			sbomContent, err := conv.ConvertOCMToSBOM(
-				ctfPath,
-				componentName,
-				parsedFormats[0],
-				mergeToolChoice,
-			)
-			if err != nil {
-				return fmt.Errorf("error processing SBOM for format %s: %w", format, err)
+		// Store the SBOMs in the CTF next to what they describe
+		if attach {
+			opts := converter.AttachOptions{Children: attachChildren, Version: attachVersion}
+			if err := conv.AttachSBOMs(sboms, parsedFormats, opts); err != nil {
+				return fmt.Errorf("error attaching SBOM to %s: %w", ctfPath, err)
 			}
+		}
 
-			// Write result in a file
-			err = os.WriteFile(currentOutputFilePath, sbomContent, 0644)
+		// Push the SBOMs as referrers of the images and the component version they describe
+		if publish {
+			opts := converter.PublishOptions{ComponentRepository: publishRepo, Insecure: publishInsecure}
+			published, err := conv.PublishSBOMs(sboms, parsedFormats, opts)
 			if err != nil {
-				return fmt.Errorf("failed to write SBOM to %s: %w", currentOutputFilePath, err)
+				return fmt.Errorf("error publishing SBOMs: %w", err)
 			}
-			log.Printf("Successfully generated %s SBOM to %s\n", format, currentOutputFilePath)
+			log.Printf("Published %d SBOM artifacts\n", len(published))
+		}
+
+		// Write the conflict report alongside the SBOM
+		if err := writeConflictReport(conv); err != nil {
+			return err
 		}
 
 		log.Println("OCM to SBOM conversion process completed.")
@@ -135,15 +263,55 @@ Example:
 	},
 }
 
+// writeConflictReport writes the merge conflicts next to the output file (or to --conflict-report).
+// Nothing is written if there were no conflicts and no explicit path was requested.
+func writeConflictReport(conv *converter.CLIConverter) error {
+	conflicts := conv.Conflicts()
+	if len(conflicts.Conflicts) == 0 && conflictsPath == "" {
+		return nil
+	}
+	reportPath := conflictsPath
+	if reportPath == "" {
+		reportPath = strings.TrimSuffix(outputFilePath, filepath.Ext(outputFilePath)) + ".conflicts.json"
+	}
+	if err := conflicts.WriteFile(reportPath); err != nil {
+		return err
+	}
+	log.Printf("Merge conflict report with %d entries written to %s\n", len(conflicts.Conflicts), reportPath)
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(convertCmd)
 
 	// Defined Flags
-	convertCmd.Flags().StringVarP(&formatStr, "format", "f", "cyclonedx-json", "Target SBOM formats (e.g., 'cyclonedx-json','spdx-json','cyclonedx-yaml','spdx-yaml')")
+	convertCmd.Flags().StringVarP(&formatStr, "format", "f", "cyclonedx-json", "Target SBOM formats (e.g., 'cyclonedx-json','spdx-json','cyclonedx-yaml','cyclonedx-xml','spdx-yaml','spdx-tag-value','spdx3-json')")
+	convertCmd.Flags().StringVar(&specVersionStr, "spec-version", converter.DefaultSpecVersion.String(), "CycloneDX spec version of the output ('1.4','1.5','1.6'); older versions drop or convert unsupported fields")
 	convertCmd.Flags().StringVarP(&outputFilePath, "output", "o", "output-sbom", "Output file path for the merged/converted SBOM (e.g., 'sbom.cdx.json').")
 
 	// Tools to choose from
-	convertCmd.Flags().StringVar(&mergeToolChoice, "merge-tool", "native", "Tool to use for merging SBOMs ('native','cyclonedx-cli','hoppr')")
+	convertCmd.Flags().StringVar(&mergeToolChoice, "merge-tool", "native", "Tool to use for merging SBOMs ('native','cyclonedx-cli', or 'exec:<command>' for an external merger, see docs/merge-protocol.md)")
+	convertCmd.Flags().StringVar(&mergeModeChoice, "merge-mode", "hierarchical", "How SBOMs are merged: 'hierarchical' nests components, 'flat' keeps all components top level linked by dependencies")
+	convertCmd.Flags().StringVar(&bomRefScheme, "bom-ref-scheme", "namespaced", "How bom-refs are assigned: 'namespaced' prefixes refs per merge level, 'ocm' uses stable OCM identities (ocm:component/<name>@<version>/resource/<name>)")
+	convertCmd.Flags().StringVar(&mergePolicySpec, "merge-policy", "", "Field-level strategies for components sharing a purl in flat merges and with --dedup, e.g. 'licenses=union,hashes=union,cpe=fail,supplier=prefer-newest' (strategies: keep-first, union, prefer-newest, fail)")
+	convertCmd.Flags().StringVar(&conflictsPath, "conflict-report", "", "Output path for the merge conflict report (default: next to the output file as *.conflicts.json)")
+	convertCmd.Flags().BoolVar(&deduplicate, "dedup", false, "Keep one component per package across images, identified by purl (hashes as fallback)")
+	convertCmd.Flags().StringSliceVar(&labelAllow, "label-allow", nil, "OCM labels exported as 'ocm:label:' properties, as name patterns (e.g. 'acme.org/*'); default: all labels")
+	convertCmd.Flags().StringSliceVar(&labelDeny, "label-deny", nil, "OCM labels never exported, as name patterns; takes precedence over --label-allow")
+	convertCmd.Flags().StringVar(&supplierConfig, "supplier-config", "", "YAML or JSON file overriding the suppliers derived from OCM providers, by provider name or component name pattern")
+	convertCmd.Flags().StringArrayVar(&verifyKeys, "verify-signature", nil, "Verify the named signature of every component version before scanning, as '<signature name>=<public key or certificate PEM>' (RSA or ECDSA); repeatable")
+	convertCmd.Flags().StringVar(&verifyCABundle, "verify-ca-bundle", "", "PEM bundle of CA certificates that signing certificates given to --verify-signature must chain to")
+	convertCmd.Flags().BoolVar(&attach, "attach", false, "Store the merged SBOM in the CTF as local blob resource 'sbom' of type 'sbom' on the root component version, one per format")
+	convertCmd.Flags().BoolVar(&attachChildren, "attach-children", false, "With --attach, also store the SBOM of every referenced component version on that version")
+	convertCmd.Flags().StringVar(&attachVersion, "attach-version", "", "With --attach, store the root component as this new version instead of updating the scanned one")
+	convertCmd.Flags().BoolVar(&publish, "publish", false, "Push each resource SBOM as OCI referrer of the scanned image and the merged SBOM as referrer of the root component version manifest, one artifact per format")
+	convertCmd.Flags().StringVar(&publishRepo, "publish-repository", "", "With --publish, the OCI repository holding the component versions (e.g. 'ghcr.io/acme/ocm'); default: the OCI registry repository context of the root component")
+	convertCmd.Flags().BoolVar(&publishInsecure, "publish-insecure", false, "With --publish, allow plain HTTP registries")
+	convertCmd.Flags().BoolVar(&reproducible, "reproducible", false, "Produce byte-identical output across runs (timestamp from SOURCE_DATE_EPOCH or the descriptor creationTime, canonical ordering, content-derived serial number)")
+
+	// Progress reporting
+	convertCmd.Flags().BoolVar(&showProgress, "progress", false, "Print conversion progress (components discovered/scanned/merged, bytes pulled) to stderr")
+	convertCmd.Flags().StringVar(&eventsFilePath, "events", "", "Write a machine-readable NDJSON event stream to this file ('-' for stdout)")
 
 	// Mandatory Flags
 	// convertCmd.MarkFlagRequired("format")
@@ -0,0 +1,87 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/olisonsturm/ocm-sbom/converter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphFormatStr   string
+	graphCollapseStr string
+	graphDepth       int
+	graphOutputPath  string
+)
+
+// graphCmd draws the OCM tree of a merged SBOM
+var graphCmd = &cobra.Command{
+	Use:   "graph [SBOM_FILE]",
+	Short: "Draw the component tree of a merged SBOM as DOT or Mermaid",
+	Long: `Draw the OCM component tree of a merged CycloneDX SBOM (as produced by 'convert') as a Graphviz DOT
+or Mermaid graph. Component versions, their resources and, with --collapse full, the packages and
+their dependencies are drawn.`,
+	Example: `  ocm-sbom graph sbom.cdx.json -f dot | dot -Tsvg > components.svg
+  ocm-sbom graph sbom.cdx.json -f mermaid --collapse components --depth 2 -o components.mmd`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var opts converter.GraphOptions
+		switch strings.ToLower(strings.TrimSpace(graphFormatStr)) {
+		case "dot":
+			opts.Format = converter.GraphFormatDOT
+		case "mermaid":
+			opts.Format = converter.GraphFormatMermaid
+		default:
+			return fmt.Errorf("unsupported graph format '%s'. Supported: dot, mermaid", graphFormatStr)
+		}
+		switch strings.ToLower(strings.TrimSpace(graphCollapseStr)) {
+		case "components":
+			opts.Collapse = converter.GraphCollapseComponents
+		case "resources":
+			opts.Collapse = converter.GraphCollapseResources
+		case "full":
+			opts.Collapse = converter.GraphCollapseFull
+		default:
+			return fmt.Errorf("unsupported collapse mode '%s'. Supported: components, resources, full", graphCollapseStr)
+		}
+		opts.MaxDepth = graphDepth
+
+		bom, err := converter.NewCycloneDXProcessor().Parse(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to read SBOM: %w", err)
+		}
+
+		var buf bytes.Buffer
+		if err := converter.RenderGraph(bom, &buf, opts); err != nil {
+			return fmt.Errorf("failed to render graph: %w", err)
+		}
+		if graphOutputPath == "" || graphOutputPath == "-" {
+			_, err := os.Stdout.Write(buf.Bytes())
+			return err
+		}
+		if err := os.WriteFile(graphOutputPath, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write graph to %s: %w", graphOutputPath, err)
+		}
+		log.Printf("Successfully generated %s graph to %s\n", opts.Format, graphOutputPath)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+
+	graphCmd.Flags().StringVarP(&graphFormatStr, "format", "f", "dot", "Graph format ('dot','mermaid')")
+	graphCmd.Flags().StringVar(&graphCollapseStr, "collapse", "resources", "Nodes to draw: 'components' (component versions only), 'resources' (with their resources), 'full' (with packages and their dependencies)")
+	graphCmd.Flags().IntVar(&graphDepth, "depth", 0, "Maximum number of component levels below the root (0 for all)")
+	graphCmd.Flags().StringVarP(&graphOutputPath, "output", "o", "-", "Output file path ('-' for stdout)")
+}
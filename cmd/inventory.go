@@ -0,0 +1,83 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/olisonsturm/ocm-sbom/converter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	inventoryFormatStr  string
+	inventoryColumnsStr string
+	inventoryOutputPath string
+)
+
+// inventoryCmd exports the packages of a merged SBOM as a spreadsheet
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory [SBOM_FILE]",
+	Short: "Export the packages of a merged SBOM as CSV or TSV",
+	Long: `Export one row per package of a merged CycloneDX SBOM (as produced by 'convert') with the OCM
+component version, resource and image it was found in.
+
+Available columns: ` + strings.Join(converter.InventoryColumns, ", "),
+	Example: `  ocm-sbom inventory sbom.cdx.json -o inventory.csv
+  ocm-sbom inventory sbom.cdx.json -f tsv --columns name,version,licenses,path`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var format converter.InventoryFormat
+		switch strings.ToLower(strings.TrimSpace(inventoryFormatStr)) {
+		case "csv":
+			format = converter.InventoryFormatCSV
+		case "tsv":
+			format = converter.InventoryFormatTSV
+		default:
+			return fmt.Errorf("unsupported inventory format '%s'. Supported: csv, tsv", inventoryFormatStr)
+		}
+		columns, err := converter.ParseInventoryColumns(inventoryColumnsStr)
+		if err != nil {
+			return fmt.Errorf("invalid --columns: %w", err)
+		}
+
+		bom, err := converter.NewCycloneDXProcessor().Parse(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to read SBOM: %w", err)
+		}
+		rows, err := converter.BuildInventory(bom)
+		if err != nil {
+			return fmt.Errorf("failed to build inventory: %w", err)
+		}
+
+		var buf bytes.Buffer
+		if err := converter.WriteInventory(rows, &buf, format, columns); err != nil {
+			return err
+		}
+		if inventoryOutputPath == "" || inventoryOutputPath == "-" {
+			_, err := os.Stdout.Write(buf.Bytes())
+			return err
+		}
+		if err := os.WriteFile(inventoryOutputPath, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write inventory to %s: %w", inventoryOutputPath, err)
+		}
+		log.Printf("Successfully exported %d packages to %s\n", len(rows), inventoryOutputPath)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(inventoryCmd)
+
+	inventoryCmd.Flags().StringVarP(&inventoryFormatStr, "format", "f", "csv", "Inventory format ('csv','tsv')")
+	inventoryCmd.Flags().StringVar(&inventoryColumnsStr, "columns", strings.Join(converter.DefaultInventoryColumns, ","), "Comma separated columns to export")
+	inventoryCmd.Flags().StringVarP(&inventoryOutputPath, "output", "o", "-", "Output file path ('-' for stdout)")
+}
@@ -0,0 +1,88 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/olisonsturm/ocm-sbom/converter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportFormatStr  string
+	reportOutputPath string
+)
+
+// reportCmd renders a merged SBOM as a human readable report
+var reportCmd = &cobra.Command{
+	Use:   "report [SBOM_FILE]",
+	Short: "Render a merged SBOM as an HTML or Markdown report",
+	Long: `Render a merged CycloneDX SBOM (as produced by 'convert') as a self-contained HTML page or a Markdown summary.
+The report shows the OCM component tree, the images of every component version, package counts,
+a license breakdown and the vulnerabilities, if the SBOM contains any.`,
+	Example: `  ocm-sbom report sbom.cdx.json -o report.html
+  ocm-sbom report sbom.cdx.json -f html,markdown -o report`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputPath := args[0]
+
+		var formats []converter.ReportFormat
+		for _, f := range strings.Split(reportFormatStr, ",") {
+			switch strings.ToLower(strings.TrimSpace(f)) {
+			case "html":
+				formats = append(formats, converter.ReportFormatHTML)
+			case "markdown", "md":
+				formats = append(formats, converter.ReportFormatMarkdown)
+			default:
+				return fmt.Errorf("unsupported report format '%s'. Supported: html, markdown", f)
+			}
+		}
+
+		bom, err := converter.NewCycloneDXProcessor().Parse(inputPath)
+		if err != nil {
+			return fmt.Errorf("failed to read SBOM: %w", err)
+		}
+		report, err := converter.BuildReport(bom)
+		if err != nil {
+			return fmt.Errorf("failed to build report: %w", err)
+		}
+
+		for _, format := range formats {
+			outputPath := reportOutputPath
+			if outputPath == "" || len(formats) > 1 {
+				base := outputPath
+				if base == "" {
+					base = strings.TrimSuffix(inputPath, filepath.Ext(inputPath))
+				}
+				outputPath = strings.TrimSuffix(base, filepath.Ext(base)) + format.Extension()
+			}
+
+			var buf bytes.Buffer
+			if err := converter.RenderReport(report, &buf, format); err != nil {
+				return err
+			}
+			if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+				return fmt.Errorf("failed to write report to %s: %w", outputPath, err)
+			}
+			log.Printf("Successfully generated %s report to %s\n", format, outputPath)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().StringVarP(&reportFormatStr, "format", "f", "html", "Report formats ('html','markdown')")
+	reportCmd.Flags().StringVarP(&reportOutputPath, "output", "o", "", "Output file path (default: next to the SBOM with the format's extension)")
+}
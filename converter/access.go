@@ -0,0 +1,271 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
+)
+
+// accessPropertyPrefix starts the names of the properties holding access specifications that have
+// no external reference equivalent, as raw JSON: "ocm:access:resource:<name>" and
+// "ocm:access:source:<name>", followed by the extra identity if there is one.
+const accessPropertyPrefix = "ocm:access:"
+
+// accessSpec decodes an access specification into its fields.
+func accessSpec(access interface{}) (map[string]interface{}, error) {
+	if access == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(access)
+	if err != nil {
+		return nil, err
+	}
+	var spec map[string]interface{}
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// accessType returns the type of an access specification without version, in lower case, e.g.
+// "ociartifact" for "ociArtifact/v1".
+func accessType(spec map[string]interface{}) string {
+	t, _ := spec["type"].(string)
+	if i := strings.Index(t, "/"); i >= 0 {
+		t = t[:i]
+	}
+	return strings.ToLower(t)
+}
+
+// AccessExternalReferences translates an OCM access specification into external references
+// telling where the artifact can be fetched. repository is the location of the component version
+// as OCM reference ("<repository>//<component>:<version>"), used for local blobs. ok is false for
+// access types without an equivalent.
+func AccessExternalReferences(spec map[string]interface{}, repository string, digest *runtime.Digest) (refs []cyclonedx.ExternalReference, ok bool) {
+	field := func(key string) string {
+		s, _ := spec[key].(string)
+		return s
+	}
+	ref := cyclonedx.ExternalReference{Type: cyclonedx.ERTypeDistribution}
+	var pinned string // digest identifying the fetched artifact, "<algorithm>:<hex>"
+
+	switch accessType(spec) {
+	case "ociartifact", "ociregistry", "ociimage":
+		ref.URL = field("imageReference")
+		_, _, pinned = splitImageReference(ref.URL)
+	case "localblob":
+		ref.URL = repository
+		ref.Comment = "localBlob " + field("localReference")
+		if mediaType := field("mediaType"); mediaType != "" {
+			ref.Comment += " (" + mediaType + ")"
+		}
+		pinned = field("localReference")
+		// A global access points to a copy of the blob that can be fetched without OCM; it may be
+		// stored differently, so the blob digest does not apply to it
+		if global, isMap := spec["globalAccess"].(map[string]interface{}); isMap {
+			if globalRefs, known := AccessExternalReferences(global, repository, nil); known {
+				refs = append(refs, globalRefs...)
+			}
+		}
+	case "helm":
+		ref.URL = field("helmRepository")
+		ref.Comment = "helm chart " + field("helmChart")
+		if version := field("version"); version != "" {
+			ref.Comment += " " + version
+		}
+	case "s3":
+		ref.URL = fmt.Sprintf("s3://%s/%s", field("bucket"), strings.TrimPrefix(field("key"), "/"))
+		if version := field("version"); version != "" {
+			ref.Comment = "version " + version
+		}
+	case "wget", "http":
+		ref.URL = field("url")
+	case "npm":
+		ref.URL = strings.TrimSuffix(field("registry"), "/") + "/" + field("package")
+		ref.Comment = field("package") + "@" + field("version")
+	case "maven":
+		ref.URL = field("repoUrl")
+		ref.Comment = strings.Join([]string{field("groupId"), field("artifactId"), field("version")}, ":")
+	case "github":
+		ref.Type = cyclonedx.ERTypeVCS
+		ref.URL = field("repoUrl")
+		ref.Comment = "commit " + field("commit")
+	case "git":
+		ref.Type = cyclonedx.ERTypeVCS
+		ref.URL = field("repository")
+		if commit := field("commit"); commit != "" {
+			ref.Comment = "commit " + commit
+		} else if r := field("ref"); r != "" {
+			ref.Comment = "ref " + r
+		}
+	default:
+		return nil, false
+	}
+	if ref.URL == "" {
+		return refs, len(refs) > 0
+	}
+
+	if hash, found := digestHash(pinned); found {
+		ref.Hashes = &[]cyclonedx.Hash{hash}
+	} else if hash, found := resourceDigestHash(runtime.Resource{Digest: digest}); found {
+		ref.Hashes = &[]cyclonedx.Hash{hash}
+	}
+	return append([]cyclonedx.ExternalReference{ref}, refs...), true
+}
+
+// digestHash maps an OCI digest ("sha256:<hex>") to a CycloneDX hash.
+func digestHash(digest string) (cyclonedx.Hash, bool) {
+	algorithm, value, found := strings.Cut(digest, ":")
+	if !found || value == "" {
+		return cyclonedx.Hash{}, false
+	}
+	return resourceDigestHash(runtime.Resource{Digest: &runtime.Digest{HashAlgorithm: algorithm, Value: value}})
+}
+
+// accessReferences returns the external references for the resources and sources of a component
+// version, and properties holding the raw access specifications it cannot translate. Sources
+// outside version control are build inputs and referenced as build-meta. Scanned resources are
+// left out; their references are on the node of the resource (see addResourceAccessReferences).
+func (c *CLIConverter) accessReferences(descriptor *runtime.Descriptor) ([]cyclonedx.ExternalReference, []cyclonedx.Property) {
+	repository := c.componentRepository(descriptor)
+
+	var refs []cyclonedx.ExternalReference
+	var props []cyclonedx.Property
+	add := func(kind, name string, extraIdentity map[string]string, access interface{}, digest *runtime.Digest) {
+		spec, err := accessSpec(access)
+		if err != nil {
+			log.Printf("Warning: could not read access of %s %s: %v", kind, name, err)
+			return
+		}
+		if spec == nil {
+			return
+		}
+		identity := name + formatIdentity(extraIdentity)
+		found, ok := AccessExternalReferences(spec, repository, digest)
+		if !ok {
+			raw, err := json.Marshal(spec)
+			if err != nil {
+				return
+			}
+			value, err := canonicalLabelValue(raw)
+			if err != nil {
+				return
+			}
+			props = append(props, cyclonedx.Property{Name: accessPropertyPrefix + kind + ":" + identity, Value: value})
+			return
+		}
+		for _, ref := range found {
+			if kind == "source" && ref.Type == cyclonedx.ERTypeDistribution {
+				ref.Type = cyclonedx.ERTypeBuildMeta
+			}
+			prefix := kind + " " + identity
+			if ref.Comment != "" {
+				ref.Comment = prefix + ": " + ref.Comment
+			} else {
+				ref.Comment = prefix
+			}
+			refs = append(refs, ref)
+		}
+	}
+
+	for _, res := range descriptor.Component.Resources {
+		if !c.scannedResource(descriptor, res) {
+			add("resource", res.Name, res.ExtraIdentity, res.Access, res.Digest)
+		}
+	}
+	for _, src := range descriptor.Component.Sources {
+		add("source", src.Name, src.ExtraIdentity, src.Access, nil)
+	}
+	return refs, props
+}
+
+// scannedResource reports whether an SBOM of the resource was generated, which makes it a node of its own.
+func (c *CLIConverter) scannedResource(descriptor *runtime.Descriptor, resource runtime.Resource) bool {
+	return slices.ContainsFunc(c.resourceSBOMs, func(rs resourceSBOM) bool {
+		return rs.Component == descriptor.Component.Name && rs.Version == descriptor.Component.Version &&
+			rs.Resource.Name == resource.Name && maps.Equal(rs.Resource.ExtraIdentity, resource.ExtraIdentity)
+	})
+}
+
+// addResourceAccessReferences adds the external references of a resource to the scanned artifact
+// of its SBOM.
+func (c *CLIConverter) addResourceAccessReferences(bom *cyclonedx.BOM, descriptor *runtime.Descriptor, resource runtime.Resource) {
+	if bom == nil || bom.Metadata == nil || bom.Metadata.Component == nil {
+		return
+	}
+	spec, err := accessSpec(resource.Access)
+	if err != nil || spec == nil {
+		return
+	}
+	refs, ok := AccessExternalReferences(spec, c.componentRepository(descriptor), resource.Digest)
+	if !ok || len(refs) == 0 {
+		return
+	}
+	node := bom.Metadata.Component
+	if node.ExternalReferences == nil {
+		node.ExternalReferences = &[]cyclonedx.ExternalReference{}
+	}
+	*node.ExternalReferences = append(*node.ExternalReferences, refs...)
+}
+
+// componentRepository returns the OCM reference of a component version: the most recent
+// repository context of its descriptor or, without one, the path of the CTF being converted.
+func (c *CLIConverter) componentRepository(descriptor *runtime.Descriptor) string {
+	location := ""
+	if contexts := descriptor.Component.RepositoryContexts; len(contexts) > 0 {
+		if spec, err := accessSpec(&contexts[len(contexts)-1]); err == nil {
+			baseURL, _ := spec["baseUrl"].(string)
+			subPath, _ := spec["subPath"].(string)
+			filePath, _ := spec["filePath"].(string)
+			switch {
+			case baseURL != "":
+				location = strings.TrimSuffix(baseURL, "/")
+				if subPath != "" {
+					location += "/" + strings.Trim(subPath, "/")
+				}
+			case filePath != "":
+				location = "file://" + filePath
+			}
+		}
+	}
+	// The CTF path as given, like in the OCM reference the conversion was started with; resolving it
+	// would expose the local file layout and differ between machines
+	if location == "" && c.ctfPath != "" {
+		location = filepath.ToSlash(filepath.Clean(c.ctfPath))
+	}
+	if location == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s//%s:%s", location, descriptor.Component.Name, descriptor.Component.Version)
+}
+
+// formatIdentity formats an extra identity as "[key=value,...]" sorted by key, or "" if empty.
+func formatIdentity(identity map[string]string) string {
+	if len(identity) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(identity))
+	for key := range identity {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + identity[key]
+	}
+	return "[" + strings.Join(pairs, ",") + "]"
+}
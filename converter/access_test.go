@@ -0,0 +1,170 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
+	ocmruntime "ocm.software/open-component-model/bindings/go/runtime"
+)
+
+func TestAccessReferencesLeaveScannedResourcesToTheirNodes(t *testing.T) {
+	resource := func(name, access string) runtime.Resource {
+		r := runtime.Resource{Type: "ociImage", Access: &ocmruntime.Raw{Data: []byte(access)}}
+		r.Name = name
+		return r
+	}
+	descriptor := &runtime.Descriptor{}
+	descriptor.Component.Name = "acme.org/app"
+	descriptor.Component.Version = "1.0.0"
+	descriptor.Component.Resources = []runtime.Resource{
+		resource("image", `{"type":"ociArtifact/v1","imageReference":"ghcr.io/acme/app:1.0.0"}`),
+		resource("config", `{"type":"localBlob/v1","localReference":"sha256:bb","mediaType":"application/json"}`),
+	}
+
+	c := &CLIConverter{ctfPath: "./transport/ctf/"}
+	c.resourceSBOMs = []resourceSBOM{{Component: "acme.org/app", Version: "1.0.0", Resource: descriptor.Component.Resources[0]}}
+
+	refs, _ := c.accessReferences(descriptor)
+	if len(refs) != 1 {
+		t.Fatalf("references = %+v, want the local blob only", refs)
+	}
+	// The CTF is referenced as given, so the SBOM does not depend on where it was converted
+	if want := "transport/ctf//acme.org/app:1.0.0"; refs[0].URL != want {
+		t.Errorf("local blob URL = %q, want %q", refs[0].URL, want)
+	}
+	if !strings.HasPrefix(refs[0].Comment, "resource config:") {
+		t.Errorf("comment = %q, want the config resource", refs[0].Comment)
+	}
+}
+
+func TestAccessExternalReferences(t *testing.T) {
+	const repository = "ghcr.io/acme//acme.org/app:1.0.0"
+	sha256 := func(value string) *[]cyclonedx.Hash {
+		return &[]cyclonedx.Hash{{Algorithm: cyclonedx.HashAlgoSHA256, Value: value}}
+	}
+	tests := []struct {
+		name   string
+		access string
+		digest *runtime.Digest
+		want   []cyclonedx.ExternalReference
+	}{
+		{
+			name:   "ociArtifact pinned by digest",
+			access: `{"type":"ociArtifact/v1","imageReference":"ghcr.io/acme/app:1.0.0@sha256:aa11"}`,
+			want:   []cyclonedx.ExternalReference{{Type: cyclonedx.ERTypeDistribution, URL: "ghcr.io/acme/app:1.0.0@sha256:aa11", Hashes: sha256("aa11")}},
+		},
+		{
+			name:   "ociArtifact by tag uses the resource digest",
+			access: `{"type":"ociArtifact","imageReference":"ghcr.io/acme/app:1.0.0"}`,
+			digest: &runtime.Digest{HashAlgorithm: "SHA-256", Value: "bb22"},
+			want:   []cyclonedx.ExternalReference{{Type: cyclonedx.ERTypeDistribution, URL: "ghcr.io/acme/app:1.0.0", Hashes: sha256("bb22")}},
+		},
+		{
+			name:   "localBlob",
+			access: `{"type":"localBlob/v1","localReference":"sha256:cc33","mediaType":"application/json"}`,
+			want:   []cyclonedx.ExternalReference{{Type: cyclonedx.ERTypeDistribution, URL: repository, Comment: "localBlob sha256:cc33 (application/json)", Hashes: sha256("cc33")}},
+		},
+		{
+			name:   "localBlob with globalAccess",
+			access: `{"type":"localBlob","localReference":"sha256:cc33","globalAccess":{"type":"ociArtifact","imageReference":"ghcr.io/acme/blob:1.0.0"}}`,
+			digest: &runtime.Digest{HashAlgorithm: "SHA-256", Value: "ff00"},
+			want: []cyclonedx.ExternalReference{
+				{Type: cyclonedx.ERTypeDistribution, URL: repository, Comment: "localBlob sha256:cc33", Hashes: sha256("cc33")},
+				{Type: cyclonedx.ERTypeDistribution, URL: "ghcr.io/acme/blob:1.0.0"},
+			},
+		},
+		{
+			name:   "helm",
+			access: `{"type":"helm/v1","helmRepository":"https://charts.acme.org","helmChart":"app","version":"1.0.0"}`,
+			want:   []cyclonedx.ExternalReference{{Type: cyclonedx.ERTypeDistribution, URL: "https://charts.acme.org", Comment: "helm chart app 1.0.0"}},
+		},
+		{
+			name:   "s3",
+			access: `{"type":"s3/v1","bucket":"releases","key":"/app/app.tgz","version":"7"}`,
+			want:   []cyclonedx.ExternalReference{{Type: cyclonedx.ERTypeDistribution, URL: "s3://releases/app/app.tgz", Comment: "version 7"}},
+		},
+		{
+			name:   "wget",
+			access: `{"type":"wget","url":"https://downloads.acme.org/app.tgz"}`,
+			want:   []cyclonedx.ExternalReference{{Type: cyclonedx.ERTypeDistribution, URL: "https://downloads.acme.org/app.tgz"}},
+		},
+		{
+			name:   "http",
+			access: `{"type":"http/v1","url":"https://downloads.acme.org/app.zip"}`,
+			want:   []cyclonedx.ExternalReference{{Type: cyclonedx.ERTypeDistribution, URL: "https://downloads.acme.org/app.zip"}},
+		},
+		{
+			name:   "github",
+			access: `{"type":"gitHub/v1","repoUrl":"https://github.com/acme/app","commit":"4f2a"}`,
+			want:   []cyclonedx.ExternalReference{{Type: cyclonedx.ERTypeVCS, URL: "https://github.com/acme/app", Comment: "commit 4f2a"}},
+		},
+		{
+			name:   "git",
+			access: `{"type":"git/v1alpha1","repository":"https://git.acme.org/app.git","ref":"refs/tags/v1.0.0"}`,
+			want:   []cyclonedx.ExternalReference{{Type: cyclonedx.ERTypeVCS, URL: "https://git.acme.org/app.git", Comment: "ref refs/tags/v1.0.0"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := accessSpec(&ocmruntime.Raw{Data: []byte(tt.access)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			refs, ok := AccessExternalReferences(spec, repository, tt.digest)
+			if !ok {
+				t.Fatalf("access type not translated")
+			}
+			got, _ := json.Marshal(refs)
+			want, _ := json.Marshal(tt.want)
+			if string(got) != string(want) {
+				t.Errorf("references =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+
+	if _, ok := AccessExternalReferences(map[string]interface{}{"type": "custom/v1"}, repository, nil); ok {
+		t.Errorf("unknown access type translated")
+	}
+}
+
+func TestAccessReferencesOfSourcesAndUnknownTypes(t *testing.T) {
+	descriptor := &runtime.Descriptor{}
+	descriptor.Component.Name = "acme.org/app"
+	descriptor.Component.Version = "1.0.0"
+	chart := runtime.Resource{Type: "helmChart", Access: &ocmruntime.Raw{Data: []byte(`{"type":"custom/v9","z":1,"a":"x"}`)}}
+	chart.Name = "chart"
+	chart.ExtraIdentity = ocmruntime.Identity{"arch": "arm64"}
+	descriptor.Component.Resources = []runtime.Resource{chart}
+	archive := runtime.Source{Type: "archive", Access: &ocmruntime.Raw{Data: []byte(`{"type":"wget","url":"https://downloads.acme.org/app-src.tgz"}`)}}
+	archive.Name = "archive"
+	repo := runtime.Source{Type: "git", Access: &ocmruntime.Raw{Data: []byte(`{"type":"gitHub","repoUrl":"https://github.com/acme/app","commit":"4f2a"}`)}}
+	repo.Name = "repo"
+	descriptor.Component.Sources = []runtime.Source{archive, repo}
+
+	refs, props := (&CLIConverter{}).accessReferences(descriptor)
+
+	// Sources outside version control are build inputs
+	want := []cyclonedx.ExternalReference{
+		{Type: cyclonedx.ERTypeBuildMeta, URL: "https://downloads.acme.org/app-src.tgz", Comment: "source archive"},
+		{Type: cyclonedx.ERTypeVCS, URL: "https://github.com/acme/app", Comment: "source repo: commit 4f2a"},
+	}
+	got, _ := json.Marshal(refs)
+	wantJSON, _ := json.Marshal(want)
+	if string(got) != string(wantJSON) {
+		t.Errorf("references =\n%s\nwant\n%s", got, wantJSON)
+	}
+
+	// Unknown access types are kept as canonical JSON
+	if len(props) != 1 || props[0].Name != "ocm:access:resource:chart[arch=arm64]" || props[0].Value != `{"a":"x","type":"custom/v9","z":1}` {
+		t.Errorf("properties = %+v, want the raw access of chart", props)
+	}
+}
@@ -0,0 +1,289 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"ocm.software/open-component-model/bindings/go/blob/inmemory"
+	"ocm.software/open-component-model/bindings/go/ctf"
+	v4alpha1 "ocm.software/open-component-model/bindings/go/descriptor/normalisation/json/v4alpha1"
+	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
+	v2 "ocm.software/open-component-model/bindings/go/descriptor/v2"
+	"ocm.software/open-component-model/bindings/go/oci"
+	ocmruntime "ocm.software/open-component-model/bindings/go/runtime"
+)
+
+// SBOMResourceType is the OCM resource type of attached SBOMs.
+const SBOMResourceType = "sbom"
+
+// sbomFormatIdentity is the extra identity key telling attached SBOMs of different formats apart.
+const sbomFormatIdentity = "format"
+
+// MediaType returns the media type of SBOM documents in the format.
+func (f SBOMFormat) MediaType() string {
+	switch f {
+	case FormatCycloneDXJSON:
+		return "application/vnd.cyclonedx+json"
+	case FormatCycloneDXXML:
+		return "application/vnd.cyclonedx+xml"
+	case FormatCycloneDXYAML:
+		return "application/vnd.cyclonedx+yaml"
+	case FormatSPDXJSON:
+		return "application/spdx+json"
+	case FormatSPDX3JSON:
+		// SPDX 3 JSON-LD is told apart from SPDX 2.3 JSON, which uses application/spdx+json
+		return "application/vnd.spdx3+json"
+	case FormatSPDXYAML:
+		return "application/spdx+yaml"
+	case FormatSPDXTagValue:
+		return "text/spdx"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// SBOMAttachment is an SBOM document to store on a component version.
+type SBOMAttachment struct {
+	Component string
+	Version   string
+	Format    SBOMFormat
+	Content   []byte
+}
+
+// AttachOptions controls how SBOMs are stored in the CTF.
+type AttachOptions struct {
+	// Children also stores the SBOM of each referenced component version, covering its subtree,
+	// on that version.
+	Children bool
+	// Version stores the root component as this new version instead of updating the scanned one.
+	Version string
+}
+
+// componentSBOM is the merged SBOM of a component version and its subtree.
+type componentSBOM struct {
+	Name    string
+	Version string
+	Path    string
+	// CreationTime is the creationTime of the component descriptor, the timestamp of reproducible SBOMs.
+	CreationTime string
+	// Supplier is the supplier of the component version, reported as metadata.supplier.
+	Supplier *cyclonedx.OrganizationalEntity
+}
+
+// componentSBOMOf returns the merged SBOM of a component version of the last conversion, or just
+// its identity if it has none.
+func (c *CLIConverter) componentSBOMOf(name, version string) componentSBOM {
+	for _, sbom := range c.componentSBOMs {
+		if sbom.Name == name && sbom.Version == version {
+			return sbom
+		}
+	}
+	return componentSBOM{Name: name, Version: version}
+}
+
+// AttachSBOMs stores the SBOMs of the last conversion in its CTF as local blob resources of type
+// sbom: the merged SBOM in every format on the root component version and, with
+// AttachOptions.Children, the SBOM of every referenced component version on that version.
+func (c *CLIConverter) AttachSBOMs(sboms map[SBOMFormat][]byte, formats []SBOMFormat, opts AttachOptions) error {
+	if len(c.componentSBOMs) == 0 {
+		return fmt.Errorf("no SBOMs to attach")
+	}
+	root := c.componentSBOMs[0]
+
+	var attachments []SBOMAttachment
+	for _, format := range formats {
+		attachments = append(attachments, SBOMAttachment{Component: root.Name, Version: root.Version, Format: format, Content: sboms[format]})
+	}
+	if opts.Children {
+		for _, child := range c.componentSBOMs[1:] {
+			rendered, err := c.renderSBOM(child, child.Path, formats)
+			if err != nil {
+				return fmt.Errorf("error rendering SBOM of %s:%s: %w", child.Name, child.Version, err)
+			}
+			for _, format := range formats {
+				attachments = append(attachments, SBOMAttachment{Component: child.Name, Version: child.Version, Format: format, Content: rendered[format]})
+			}
+		}
+	}
+
+	repo, err := openRepository(c.ctfPath, ctf.O_RDWR)
+	if err != nil {
+		return fmt.Errorf("error opening repository for writing: %w", err)
+	}
+	return attachSBOMs(repo, root.Name, root.Version, attachments, opts.Version)
+}
+
+// attachSBOMs adds the attachments as local blob resources to their component versions and
+// writes every changed component version back. Parents of changed component versions get the
+// recomputed digests in their references and are written back as well, up to the root, which is
+// stored as newVersion if given, with its local blobs copied over. Signatures of changed
+// component versions no longer match and are dropped.
+func attachSBOMs(repo oci.ComponentVersionRepository, rootName, rootVersion string, attachments []SBOMAttachment, newVersion string) error {
+	ctx := context.Background()
+	id := func(n, v string) string { return fmt.Sprintf("%s:%s", n, v) }
+	rootID := id(rootName, rootVersion)
+
+	byComponent := make(map[string][]SBOMAttachment)
+	for _, a := range attachments {
+		byComponent[id(a.Component, a.Version)] = append(byComponent[id(a.Component, a.Version)], a)
+	}
+
+	descriptors := make(map[string]*runtime.Descriptor)
+	changed := make(map[string]bool)
+	var order []string // post-order: children before their parents
+
+	var visit func(name, version string) error
+	visit = func(name, version string) error {
+		nid := id(name, version)
+		if _, seen := descriptors[nid]; seen {
+			return nil
+		}
+		descriptor, err := repo.GetComponentVersion(ctx, name, version)
+		if err != nil {
+			return fmt.Errorf("could not get component version %s: %w", nid, err)
+		}
+		descriptors[nid] = descriptor
+		for _, ref := range descriptor.Component.References {
+			if err := visit(ref.Component, ref.Version); err != nil {
+				return err
+			}
+		}
+		order = append(order, nid)
+		return nil
+	}
+	if err := visit(rootName, rootVersion); err != nil {
+		return err
+	}
+	for nid := range byComponent {
+		if _, ok := descriptors[nid]; !ok {
+			return fmt.Errorf("component version %s is not part of %s", nid, rootID)
+		}
+	}
+
+	for _, nid := range order {
+		descriptor := descriptors[nid]
+		if nid == rootID && newVersion != "" {
+			if err := copyLocalResources(ctx, repo, descriptor, newVersion, byComponent[nid]); err != nil {
+				return err
+			}
+			descriptor.Component.Version = newVersion
+			changed[nid] = true
+		}
+
+		for _, a := range byComponent[nid] {
+			if err := addSBOMResource(ctx, repo, descriptor, a); err != nil {
+				return err
+			}
+			changed[nid] = true
+		}
+
+		for i := range descriptor.Component.References {
+			ref := &descriptor.Component.References[i]
+			childID := id(ref.Component, ref.Version)
+			if !changed[childID] {
+				continue
+			}
+			digest, err := DescriptorDigest(descriptors[childID], v4alpha1.Algorithm, crypto.SHA256)
+			if err != nil {
+				return fmt.Errorf("failed to compute digest of %s: %w", childID, err)
+			}
+			ref.Digest = runtime.Digest{HashAlgorithm: "SHA-256", NormalisationAlgorithm: v4alpha1.Algorithm, Value: digest}
+			changed[nid] = true
+		}
+		if !changed[nid] {
+			continue
+		}
+
+		for _, sig := range descriptor.Signatures {
+			log.Printf("Warning: signature %q of %s no longer matches the changed descriptor and was removed; sign the component version again", sig.Name, nid)
+		}
+		descriptor.Signatures = nil
+
+		if err := repo.AddComponentVersion(ctx, descriptor); err != nil {
+			return fmt.Errorf("failed to write component version %s:%s: %w", descriptor.Component.Name, descriptor.Component.Version, err)
+		}
+		log.Printf("Wrote component version %s:%s", descriptor.Component.Name, descriptor.Component.Version)
+	}
+	return nil
+}
+
+// copyLocalResources stores the local blob resources of a component version again under newVersion;
+// local blobs belong to the component version they were added to and are not shared with a new
+// version. SBOMs that are replaced by one of the attachments are left out.
+func copyLocalResources(ctx context.Context, repo oci.ComponentVersionRepository, descriptor *runtime.Descriptor, newVersion string, attachments []SBOMAttachment) error {
+	name, version := descriptor.Component.Name, descriptor.Component.Version
+	for i, res := range descriptor.Component.Resources {
+		spec, err := accessSpec(res.Access)
+		if err != nil || accessType(spec) != "localblob" {
+			continue
+		}
+		if res.Name == SBOMResourceType && slices.ContainsFunc(attachments, func(a SBOMAttachment) bool {
+			return res.ExtraIdentity[sbomFormatIdentity] == string(a.Format)
+		}) {
+			continue
+		}
+
+		identity := ocmruntime.Identity{"name": res.Name}
+		if res.Version != "" {
+			identity["version"] = res.Version
+		}
+		maps.Copy(identity, res.ExtraIdentity)
+		content, _, err := repo.GetLocalResource(ctx, name, version, identity)
+		if err != nil {
+			return fmt.Errorf("failed to read resource %s of %s:%s: %w", res.Name, name, version, err)
+		}
+		copied, err := repo.AddLocalResource(ctx, name, newVersion, &res, content)
+		if err != nil {
+			return fmt.Errorf("failed to copy resource %s of %s:%s to %s: %w", res.Name, name, version, newVersion, err)
+		}
+		descriptor.Component.Resources[i] = *copied
+	}
+	return nil
+}
+
+// addSBOMResource uploads an SBOM as local blob and adds it to the descriptor, replacing an SBOM
+// resource of the same format.
+func addSBOMResource(ctx context.Context, repo oci.ComponentVersionRepository, descriptor *runtime.Descriptor, a SBOMAttachment) error {
+	mediaType := a.Format.MediaType()
+	resource := &runtime.Resource{
+		ElementMeta: runtime.ElementMeta{
+			ObjectMeta:    runtime.ObjectMeta{Name: SBOMResourceType, Version: descriptor.Component.Version},
+			ExtraIdentity: ocmruntime.Identity{sbomFormatIdentity: string(a.Format)},
+		},
+		Type:     SBOMResourceType,
+		Relation: runtime.LocalRelation,
+		Access: &v2.LocalBlob{
+			Type:      ocmruntime.NewVersionedType(v2.LocalBlobAccessType, v2.LocalBlobAccessTypeVersion),
+			MediaType: mediaType,
+		},
+	}
+	content := inmemory.New(bytes.NewReader(a.Content), inmemory.WithMediaType(mediaType))
+	uploaded, err := repo.AddLocalResource(ctx, descriptor.Component.Name, descriptor.Component.Version, resource, content)
+	if err != nil {
+		return fmt.Errorf("failed to store %s SBOM on %s:%s: %w", a.Format, descriptor.Component.Name, descriptor.Component.Version, err)
+	}
+
+	log.Printf("Attached %s SBOM to %s:%s", a.Format, descriptor.Component.Name, descriptor.Component.Version)
+
+	resources := descriptor.Component.Resources
+	for i, res := range resources {
+		if res.Name == SBOMResourceType && res.ExtraIdentity[sbomFormatIdentity] == string(a.Format) {
+			resources[i] = *uploaded
+			return nil
+		}
+	}
+	descriptor.Component.Resources = append(resources, *uploaded)
+	return nil
+}
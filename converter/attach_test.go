@@ -0,0 +1,156 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"ocm.software/open-component-model/bindings/go/blob"
+	"ocm.software/open-component-model/bindings/go/blob/inmemory"
+	"ocm.software/open-component-model/bindings/go/ctf"
+	v4alpha1 "ocm.software/open-component-model/bindings/go/descriptor/normalisation/json/v4alpha1"
+	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
+	v2 "ocm.software/open-component-model/bindings/go/descriptor/v2"
+	"ocm.software/open-component-model/bindings/go/oci"
+	ocmruntime "ocm.software/open-component-model/bindings/go/runtime"
+)
+
+// readLocalResource reads a local blob resource of a component version.
+func readLocalResource(t *testing.T, repo oci.ComponentVersionRepository, name, version string, identity ocmruntime.Identity) ([]byte, *runtime.Resource) {
+	t.Helper()
+	content, res, err := repo.GetLocalResource(context.Background(), name, version, identity)
+	if err != nil {
+		t.Fatalf("reading %v of %s:%s: %v", identity, name, version, err)
+	}
+	data := readBlob(t, content)
+	return data, res
+}
+
+// readBlob returns the content of a blob.
+func readBlob(t *testing.T, b blob.ReadOnlyBlob) []byte {
+	t.Helper()
+	rc, err := b.ReadCloser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestAttachSBOMsAsNewVersionRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo, err := openRepository(dir, ctf.O_RDWR|ctf.O_CREATE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	descriptor := func(name string) *runtime.Descriptor {
+		d := &runtime.Descriptor{}
+		d.Meta.Version = "v2"
+		d.Component.Name = name
+		d.Component.Version = "1.0.0"
+		d.Component.Provider.Name = "acme.org"
+		return d
+	}
+	child := descriptor("acme.org/child")
+	if err := repo.AddComponentVersion(ctx, child); err != nil {
+		t.Fatal(err)
+	}
+
+	root := descriptor("acme.org/root")
+	config := []byte(`{"replicas":3}`)
+	resource := &runtime.Resource{
+		ElementMeta: runtime.ElementMeta{ObjectMeta: runtime.ObjectMeta{Name: "config", Version: "1.0.0"}},
+		Type:        "blob",
+		Relation:    runtime.LocalRelation,
+		Access: &v2.LocalBlob{
+			Type:      ocmruntime.NewVersionedType(v2.LocalBlobAccessType, v2.LocalBlobAccessTypeVersion),
+			MediaType: "application/json",
+		},
+	}
+	uploaded, err := repo.AddLocalResource(ctx, root.Component.Name, root.Component.Version, resource, inmemory.New(bytes.NewReader(config), inmemory.WithMediaType("application/json")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.Component.Resources = []runtime.Resource{*uploaded}
+	ref := runtime.Reference{Component: child.Component.Name}
+	ref.Name = "child"
+	ref.Version = child.Component.Version
+	root.Component.References = []runtime.Reference{ref}
+	if err := repo.AddComponentVersion(ctx, root); err != nil {
+		t.Fatal(err)
+	}
+
+	rootSBOM := []byte(`{"bomFormat":"CycloneDX","specVersion":"1.6","version":1}`)
+	childSBOM := []byte(`{"bomFormat":"CycloneDX","specVersion":"1.6","version":2}`)
+	attachments := []SBOMAttachment{
+		{Component: "acme.org/root", Version: "1.0.0", Format: FormatCycloneDXJSON, Content: rootSBOM},
+		{Component: "acme.org/child", Version: "1.0.0", Format: FormatCycloneDXJSON, Content: childSBOM},
+	}
+	if err := attachSBOMs(repo, "acme.org/root", "1.0.0", attachments, "1.1.0"); err != nil {
+		t.Fatalf("attachSBOMs: %v", err)
+	}
+
+	// Read everything back from a fresh view of the CTF
+	repo, err = openRepository(dir, ctf.O_RDONLY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := repo.GetComponentVersion(ctx, "acme.org/root", "1.1.0")
+	if err != nil {
+		t.Fatalf("new root version not stored: %v", err)
+	}
+	sbomIdentity := ocmruntime.Identity{"name": SBOMResourceType, "version": "1.1.0", sbomFormatIdentity: string(FormatCycloneDXJSON)}
+	data, res := readLocalResource(t, repo, "acme.org/root", "1.1.0", sbomIdentity)
+	if !bytes.Equal(data, rootSBOM) {
+		t.Errorf("attached SBOM = %s, want %s", data, rootSBOM)
+	}
+	sum := sha256.Sum256(rootSBOM)
+	if res.Digest == nil || res.Digest.Value != hex.EncodeToString(sum[:]) {
+		t.Errorf("digest of the SBOM resource = %+v, want sha256 %x", res.Digest, sum)
+	}
+
+	// The local blobs of the scanned version must be readable from the new version
+	if data, _ := readLocalResource(t, repo, "acme.org/root", "1.1.0", ocmruntime.Identity{"name": "config", "version": "1.0.0"}); !bytes.Equal(data, config) {
+		t.Errorf("config of the new version = %s, want %s", data, config)
+	}
+
+	storedChild, err := repo.GetComponentVersion(ctx, "acme.org/child", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := DescriptorDigest(storedChild, v4alpha1.Algorithm, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Component.References) != 1 || stored.Component.References[0].Digest.Value != want {
+		t.Errorf("reference digest = %+v, want %s", stored.Component.References, want)
+	}
+	if data, _ := readLocalResource(t, repo, "acme.org/child", "1.0.0", ocmruntime.Identity{"name": SBOMResourceType, "version": "1.0.0", sbomFormatIdentity: string(FormatCycloneDXJSON)}); !bytes.Equal(data, childSBOM) {
+		t.Errorf("child SBOM = %s, want %s", data, childSBOM)
+	}
+
+	// The scanned version stays as it was
+	previous, err := repo.GetComponentVersion(ctx, "acme.org/root", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(previous.Component.Resources) != 1 {
+		t.Errorf("resources of the scanned version = %+v, want only config", previous.Component.Resources)
+	}
+}
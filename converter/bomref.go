@@ -0,0 +1,164 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
+)
+
+// BOMRefScheme selects how bom-refs are assigned.
+type BOMRefScheme string
+
+const (
+	// BOMRefSchemeNamespaced prefixes refs with "name@version:" of the containing component at
+	// every merge level, so refs depend on where a component sits in the tree.
+	BOMRefSchemeNamespaced BOMRefScheme = "namespaced"
+	// BOMRefSchemeOCM derives refs from the OCM identity of components and resources, so they
+	// stay the same no matter how deep a component is nested.
+	BOMRefSchemeOCM BOMRefScheme = "ocm"
+)
+
+// ocmBOMRefPrefix starts every bom-ref of the OCM scheme.
+const ocmBOMRefPrefix = "ocm:component/"
+
+// OCMComponentBOMRef returns the bom-ref of a component version: "ocm:component/<name>@<version>".
+func OCMComponentBOMRef(name, version string) string {
+	return fmt.Sprintf("%s%s@%s", ocmBOMRefPrefix, name, version)
+}
+
+// OCMResourceBOMRef returns the bom-ref of a resource of a component version:
+// "ocm:component/<name>@<version>/resource/<resource>", followed by the extra identity as
+// "[key=value,...]" sorted by key if the resource has one.
+func OCMResourceBOMRef(componentName, componentVersion, resourceName string, extraIdentity map[string]string) string {
+	ref := fmt.Sprintf("%s/resource/%s", OCMComponentBOMRef(componentName, componentVersion), resourceName)
+	return ref + formatIdentity(extraIdentity)
+}
+
+// isOCMBOMRef reports whether a bom-ref belongs to the OCM scheme. Such refs are globally
+// unique and are not namespaced again while merging.
+func isOCMBOMRef(ref string) bool {
+	return strings.HasPrefix(ref, ocmBOMRefPrefix)
+}
+
+// bomRefScheme returns the configured scheme, defaulting to BOMRefSchemeNamespaced.
+func (c *CLIConverter) bomRefScheme() BOMRefScheme {
+	if c.BOMRefScheme == "" {
+		return BOMRefSchemeNamespaced
+	}
+	return c.BOMRefScheme
+}
+
+// componentBOMRef returns the bom-ref for a component node, or "" to let the merge derive it.
+func (c *CLIConverter) componentBOMRef(name, version string) string {
+	if c.bomRefScheme() != BOMRefSchemeOCM {
+		return ""
+	}
+	return OCMComponentBOMRef(name, version)
+}
+
+// ApplyOCMResourceBOMRefs rewrites the refs of a resource SBOM produced by Syft: the scanned
+// artifact becomes the resource node and packages are placed underneath it by purl. Packages
+// without a purl, or sharing a purl with another package, keep their Syft ref under the resource.
+func ApplyOCMResourceBOMRefs(bom *cyclonedx.BOM, descriptor *runtime.Descriptor, resource runtime.Resource) {
+	if bom == nil || bom.Metadata == nil || bom.Metadata.Component == nil {
+		return
+	}
+	resourceRef := OCMResourceBOMRef(descriptor.Component.Name, descriptor.Component.Version, resource.Name, resource.ExtraIdentity)
+
+	purlCount := make(map[string]int)
+	var count func(components *[]cyclonedx.Component)
+	count = func(components *[]cyclonedx.Component) {
+		if components == nil {
+			return
+		}
+		for _, comp := range *components {
+			if comp.PackageURL != "" {
+				purlCount[comp.PackageURL]++
+			}
+			count(comp.Components)
+		}
+	}
+	count(bom.Components)
+
+	refMap := map[string]string{}
+	if old := bom.Metadata.Component.BOMRef; old != "" {
+		refMap[old] = resourceRef
+	}
+	bom.Metadata.Component.BOMRef = resourceRef
+
+	var assign func(components *[]cyclonedx.Component)
+	assign = func(components *[]cyclonedx.Component) {
+		if components == nil {
+			return
+		}
+		for i := range *components {
+			comp := &(*components)[i]
+			local := comp.BOMRef
+			if comp.PackageURL != "" && purlCount[comp.PackageURL] == 1 {
+				local = comp.PackageURL
+			}
+			if local == "" {
+				local = componentBOMRefNamespace(comp)
+			}
+			newRef := resourceRef + "/" + local
+			if comp.BOMRef != "" {
+				refMap[comp.BOMRef] = newRef
+			}
+			comp.BOMRef = newRef
+			assign(comp.Components)
+		}
+	}
+	assign(bom.Components)
+
+	remapBOMRefs(bom, func(ref string) string {
+		if mapped, ok := refMap[ref]; ok {
+			return mapped
+		}
+		return ref
+	})
+}
+
+// remapBOMRefs rewrites the references in dependencies, compositions and vulnerabilities.
+func remapBOMRefs(bom *cyclonedx.BOM, mapRef func(string) string) {
+	if bom.Dependencies != nil {
+		for i := range *bom.Dependencies {
+			dep := &(*bom.Dependencies)[i]
+			dep.Ref = mapRef(dep.Ref)
+			if dep.Dependencies != nil {
+				for j := range *dep.Dependencies {
+					(*dep.Dependencies)[j] = mapRef((*dep.Dependencies)[j])
+				}
+			}
+		}
+	}
+	if bom.Compositions != nil {
+		for _, composition := range *bom.Compositions {
+			for _, refs := range []*[]cyclonedx.BOMReference{composition.Assemblies, composition.Dependencies} {
+				if refs == nil {
+					continue
+				}
+				for j := range *refs {
+					(*refs)[j] = cyclonedx.BOMReference(mapRef(string((*refs)[j])))
+				}
+			}
+		}
+	}
+	if bom.Vulnerabilities != nil {
+		for _, vulnerability := range *bom.Vulnerabilities {
+			if vulnerability.Affects == nil {
+				continue
+			}
+			for j := range *vulnerability.Affects {
+				(*vulnerability.Affects)[j].Ref = mapRef((*vulnerability.Affects)[j].Ref)
+			}
+		}
+	}
+}
@@ -0,0 +1,192 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+// errorPropertyPrefix namespaces the properties that carry the reason of a failed lookup or scan.
+const errorPropertyPrefix = "ocm-sbom:error"
+
+// FailureKind classifies why parts of a component are missing from the SBOM.
+type FailureKind string
+
+const (
+	// FailureDescriptor means the component descriptor could not be read; nothing is known about the component.
+	FailureDescriptor FailureKind = "descriptor"
+	// FailureResource means a resource of the component could not be scanned.
+	FailureResource FailureKind = "resource"
+	// FailureMerge means SBOMs of the component or its children could not be merged.
+	FailureMerge FailureKind = "merge"
+)
+
+// ComponentFailure records a lookup, scan or merge failure for a component version.
+type ComponentFailure struct {
+	Kind      FailureKind
+	Component string
+	Version   string
+	// Resource is the name of the resource that failed, for FailureResource.
+	Resource string
+	// Parent is the "name:version" of the component referencing this one, if known.
+	Parent string
+	Reason string
+}
+
+// aggregate returns the composition aggregate describing the failure.
+func (f ComponentFailure) aggregate() cyclonedx.CompositionAggregate {
+	if f.Kind == FailureDescriptor {
+		return cyclonedx.CompositionAggregateUnknown
+	}
+	return cyclonedx.CompositionAggregateIncomplete
+}
+
+// property returns the property that stores the failure reason on the component node.
+func (f ComponentFailure) property() cyclonedx.Property {
+	name := fmt.Sprintf("%s:%s", errorPropertyPrefix, f.Kind)
+	if f.Resource != "" {
+		name = fmt.Sprintf("%s:%s", name, f.Resource)
+	}
+	return cyclonedx.Property{Name: name, Value: f.Reason}
+}
+
+// recordFailure remembers a failure so it can be recorded in the final SBOM.
+func (c *CLIConverter) recordFailure(f ComponentFailure) {
+	c.failures = append(c.failures, f)
+}
+
+// Failures returns the failures recorded by the last conversion.
+func (c *CLIConverter) Failures() []ComponentFailure {
+	return c.failures
+}
+
+// AnnotateFailures marks the component nodes affected by failures as incomplete (some resources
+// could not be scanned) or unknown (the descriptor could not be read) using compositions, and
+// adds the failure reasons as properties. Components without a node in the BOM get a placeholder
+// component, linked from their parent if the parent is part of the BOM.
+func AnnotateFailures(bom *cyclonedx.BOM, failures []ComponentFailure) {
+	if bom == nil || len(failures) == 0 {
+		return
+	}
+
+	aggregates := make(map[string]cyclonedx.CompositionAggregate)
+	var order []string
+	for _, f := range failures {
+		node := findComponentNode(bom, f.Component, f.Version)
+		if node == nil {
+			node = addPlaceholderComponent(bom, f)
+		}
+		if node.Properties == nil {
+			node.Properties = &[]cyclonedx.Property{}
+		}
+		*node.Properties = append(*node.Properties, f.property())
+
+		// Unknown outranks incomplete: if nothing could be read, partial results do not matter
+		if current, ok := aggregates[node.BOMRef]; !ok {
+			order = append(order, node.BOMRef)
+			aggregates[node.BOMRef] = f.aggregate()
+		} else if current != cyclonedx.CompositionAggregateUnknown {
+			aggregates[node.BOMRef] = f.aggregate()
+		}
+	}
+
+	if bom.Compositions == nil {
+		bom.Compositions = &[]cyclonedx.Composition{}
+	}
+	for _, ref := range order {
+		*bom.Compositions = append(*bom.Compositions, cyclonedx.Composition{
+			Aggregate:  aggregates[ref],
+			Assemblies: &[]cyclonedx.BOMReference{cyclonedx.BOMReference(ref)},
+		})
+	}
+	log.Printf("Recorded %d failures for %d components as compositions", len(failures), len(order))
+}
+
+// failuresWithin returns the failures concerning component versions described by bom: those with
+// a node in it and those referenced by one.
+func failuresWithin(bom *cyclonedx.BOM, failures []ComponentFailure) []ComponentFailure {
+	var within []ComponentFailure
+	for _, f := range failures {
+		parentName, parentVersion, _ := strings.Cut(f.Parent, ":")
+		if findComponentNode(bom, f.Component, f.Version) != nil || (f.Parent != "" && findComponentNode(bom, parentName, parentVersion) != nil) {
+			within = append(within, f)
+		}
+	}
+	return within
+}
+
+// findComponentNode returns the node representing an OCM component version, searching the
+// metadata component and the component tree.
+func findComponentNode(bom *cyclonedx.BOM, name, version string) *cyclonedx.Component {
+	matches := func(comp *cyclonedx.Component) bool {
+		return comp.Name == name && comp.Version == version && comp.Type != cyclonedx.ComponentTypeContainer
+	}
+	if bom.Metadata != nil && bom.Metadata.Component != nil && matches(bom.Metadata.Component) {
+		return ensureBOMRef(bom.Metadata.Component)
+	}
+	if bom.Components == nil {
+		return nil
+	}
+
+	stack := []*[]cyclonedx.Component{bom.Components}
+	for len(stack) > 0 {
+		components := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for i := range *components {
+			comp := &(*components)[i]
+			if matches(comp) {
+				return ensureBOMRef(comp)
+			}
+			if comp.Components != nil {
+				stack = append(stack, comp.Components)
+			}
+		}
+	}
+	return nil
+}
+
+// ensureBOMRef gives a component a bom-ref so compositions can point to it.
+func ensureBOMRef(comp *cyclonedx.Component) *cyclonedx.Component {
+	if comp.BOMRef == "" {
+		comp.BOMRef = componentBOMRefNamespace(comp)
+	}
+	return comp
+}
+
+// addPlaceholderComponent adds a top level node for a component that produced no SBOM and
+// links it from its parent's node.
+func addPlaceholderComponent(bom *cyclonedx.BOM, f ComponentFailure) *cyclonedx.Component {
+	placeholder := cyclonedx.Component{
+		Type:    cyclonedx.ComponentTypeApplication,
+		Name:    f.Component,
+		Version: f.Version,
+	}
+	placeholder.BOMRef = componentBOMRefNamespace(&placeholder)
+	if bom.Metadata != nil && bom.Metadata.Component != nil && isOCMBOMRef(bom.Metadata.Component.BOMRef) {
+		placeholder.BOMRef = OCMComponentBOMRef(f.Component, f.Version)
+	}
+
+	if f.Parent != "" {
+		parentName, parentVersion, _ := strings.Cut(f.Parent, ":")
+		if parent := findComponentNode(bom, parentName, parentVersion); parent != nil {
+			if bom.Dependencies == nil {
+				bom.Dependencies = &[]cyclonedx.Dependency{}
+			}
+			mergeDependency(bom.Dependencies, cyclonedx.Dependency{Ref: parent.BOMRef, Dependencies: &[]string{placeholder.BOMRef}})
+		}
+	}
+
+	if bom.Components == nil {
+		bom.Components = &[]cyclonedx.Component{}
+	}
+	*bom.Components = append(*bom.Components, placeholder)
+	return &(*bom.Components)[len(*bom.Components)-1]
+}
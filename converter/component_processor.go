@@ -8,14 +8,12 @@ package converter
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 
 	"github.com/CycloneDX/cyclonedx-go"
-	"github.com/anchore/clio"
 	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
 )
 
@@ -74,12 +72,7 @@ func (p *ComponentProcessor) ProcessComponent(descriptor *runtime.Descriptor, ou
 	}
 
 	// Edit the SBOM metadata
-	editOptions := CycloneDXProcessorOptions{
-		Name:       descriptor.Component.Name,
-		Version:    descriptor.Component.Version,
-		Properties: make([]cyclonedx.Property, 0),
-	}
-	if err := processor.Edit(sbom, editOptions); err != nil {
+	if err := processor.Edit(sbom, p.cliConverter.componentEditOptions(descriptor)); err != nil {
 		return "", fmt.Errorf("failed to edit SBOM metadata for component %s/%s: %w",
 			descriptor.Component.Name, descriptor.Component.Version, err)
 	}
@@ -93,60 +86,122 @@ func (p *ComponentProcessor) ProcessComponent(descriptor *runtime.Descriptor, ou
 	return mergedSBOMPath, nil
 }
 
+// componentEditOptions returns the metadata of the node of a component version: its identity,
+// labels, the supplier derived from its provider and where its resources and sources are fetched.
+func (c *CLIConverter) componentEditOptions(descriptor *runtime.Descriptor) CycloneDXProcessorOptions {
+	supplier := ProviderSupplier(descriptor, c.SupplierConfig)
+	refs, accessProps := c.accessReferences(descriptor)
+	return CycloneDXProcessorOptions{
+		Name:               descriptor.Component.Name,
+		Version:            descriptor.Component.Version,
+		Properties:         c.componentProperties(descriptor, accessProps),
+		Supplier:           supplier,
+		Manufacturer:       supplier,
+		ExternalReferences: refs,
+	}
+}
+
+// componentProperties returns the properties of the node of a component version: its labels,
+// signatures and the access specifications without external reference equivalent.
+func (c *CLIConverter) componentProperties(descriptor *runtime.Descriptor, accessProps []cyclonedx.Property) []cyclonedx.Property {
+	props := LabelProperties(descriptor.Component.Labels, c.LabelFilter)
+	props = append(props, SignatureProperties(descriptor.Signatures)...)
+	return append(props, accessProps...)
+}
+
+// editComponentSBOM applies the metadata of a component version to the subject of its merged SBOM.
+func (c *CLIConverter) editComponentSBOM(sbomPath string, descriptor *runtime.Descriptor) error {
+	if descriptor == nil {
+		return nil
+	}
+	processor := NewCycloneDXProcessor()
+	sbom, err := processor.Parse(sbomPath)
+	if err != nil {
+		return err
+	}
+	if err := processor.Edit(sbom, c.componentEditOptions(descriptor)); err != nil {
+		return err
+	}
+	return processor.Write(sbom, sbomPath, 0)
+}
+
 // generateComponentResourceSboms generates SBOMs for each relevant resource in a component.
 func (p *ComponentProcessor) generateComponentResourceSboms(descriptor *runtime.Descriptor, componentResourceSbomDir string, outputFormat SBOMFormat) ([]string, error) {
 	var componentResourceSbomFullPaths []string
 	for _, res := range descriptor.Component.Resources {
-		var accessMap map[string]interface{}
-		if res.Access != nil {
-			rawBytes, err := json.Marshal(res.Access)
-			if err != nil {
-				log.Printf("Warning: could not serialize access data for resource %s: %v", res.Name, err)
-				continue
-			}
-			if len(rawBytes) > 0 {
-				if err := json.Unmarshal(rawBytes, &accessMap); err != nil {
-					log.Printf("Warning: could not parse access data for resource %s: %v", res.Name, err)
-					continue
-				}
-			}
+		accessMap, err := accessSpec(res.Access)
+		if err != nil {
+			log.Printf("Warning: could not parse access data for resource %s: %v", res.Name, err)
+			continue
 		}
 		if accessMap == nil {
 			accessMap = make(map[string]interface{})
 		}
 		if imageRef, ok := accessMap["imageReference"].(string); ok && res.Type == "ociImage" {
 			log.Printf("Generating SBOM with Syft for OCI Image resource: %s", imageRef)
+			componentID := fmt.Sprintf("%s:%s", descriptor.Component.Name, descriptor.Component.Version)
+			p.cliConverter.report(Event{Type: EventResourceScanStarted, Component: componentID, Resource: res.Name, Image: imageRef})
 
-			// Set desired output format (single)
-			format := []string{string(outputFormat)}
 			// Create a temporary file for the SBOM
 			safeImageRef := sanitizeFilename(imageRef)
 			safeResName := sanitizeFilename(res.Name)
 			tempFilename := fmt.Sprintf("%s-%s-sbom.json", safeImageRef, safeResName)
 			tempComponentResourceSbomFullPath := filepath.Join(componentResourceSbomDir, tempFilename)
 
-			// Create syft scanner with custom config
-			config := DefaultScanConfig()
-			id := clio.Identification{
-				Name:    "ocm-syft-scanner",
-				Version: "1.0.0-dev",
-			}
-			// set desired output format (single)
-			config.OutputFormats = format
-			s := NewScanner(config, id)
+			// Create syft scanner with the effective scan config
+			s := NewScanner(p.cliConverter.scanConfig(), p.cliConverter.scanIdentification())
 
 			// Save the SBOM to a temporary file
 			s.SetOutputFile(tempComponentResourceSbomFullPath)
 
 			// Scan and write to the custom file path
 			if err := s.ScanToWriter(context.Background(), imageRef); err != nil {
-				return nil, fmt.Errorf("failed to generate SBOM for resource %s (%s): %w", res.Name, imageRef, err)
+				// Keep scanning the other resources; the failure is recorded in the final SBOM
+				log.Printf("Warning: failed to generate SBOM for resource %s (%s): %v", res.Name, imageRef, err)
+				p.cliConverter.report(Event{Type: EventResourceFailed, Component: componentID, Resource: res.Name, Image: imageRef, Error: err.Error()})
+				p.cliConverter.recordFailure(ComponentFailure{
+					Kind:      FailureResource,
+					Component: descriptor.Component.Name,
+					Version:   descriptor.Component.Version,
+					Resource:  res.Name,
+					Reason:    fmt.Sprintf("failed to generate SBOM for %s: %v", imageRef, err),
+				})
+				continue
 			}
 
 			log.Printf("SBOM generated and saved for resource %s at %s", imageRef, tempComponentResourceSbomFullPath)
+			if err := p.annotateResourceSBOM(tempComponentResourceSbomFullPath, descriptor, res, imageRef); err != nil {
+				log.Printf("Warning: could not annotate SBOM of resource %s: %v", res.Name, err)
+			}
+			p.cliConverter.resourceSBOMs = append(p.cliConverter.resourceSBOMs, resourceSBOM{
+				Component: descriptor.Component.Name,
+				Version:   descriptor.Component.Version,
+				Resource:  res,
+				ImageRef:  imageRef,
+				Path:      tempComponentResourceSbomFullPath,
+			})
+			p.cliConverter.report(Event{Type: EventResourceScanned, Component: componentID, Resource: res.Name, Image: imageRef})
 
 			componentResourceSbomFullPaths = append(componentResourceSbomFullPaths, tempComponentResourceSbomFullPath)
 		}
 	}
 	return componentResourceSbomFullPaths, nil
 }
+
+// annotateResourceSBOM makes the scanned artifact of a resource SBOM the node of the OCM resource,
+// adds where it is fetched and the resource labels and, with the OCM scheme, rewrites its refs to
+// the OCM identities.
+func (p *ComponentProcessor) annotateResourceSBOM(sbomPath string, descriptor *runtime.Descriptor, resource runtime.Resource, imageRef string) error {
+	processor := NewCycloneDXProcessor()
+	sbom, err := processor.Parse(sbomPath)
+	if err != nil {
+		return err
+	}
+	ApplyOCMResourceComponent(sbom, resource, imageRef)
+	p.cliConverter.addResourceAccessReferences(sbom, descriptor, resource)
+	p.cliConverter.addLabelProperties(sbom, resource.Labels)
+	if p.cliConverter.bomRefScheme() == BOMRefSchemeOCM {
+		ApplyOCMResourceBOMRefs(sbom, descriptor, resource)
+	}
+	return processor.Write(sbom, sbomPath, 0)
+}
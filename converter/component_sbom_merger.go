@@ -12,11 +12,108 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/CycloneDX/cyclonedx-go"
 )
 
+// MergeRequest describes a single merge step: a set of CycloneDX JSON SBOMs that are combined
+// into one SBOM for the given component.
+type MergeRequest struct {
+	// ComponentName and ComponentVersion identify the subject of the merged SBOM.
+	ComponentName    string
+	ComponentVersion string
+	// SubjectBOMRef is the bom-ref for the merged component node; empty lets the merger derive it.
+	SubjectBOMRef string
+	// InputPaths are the CycloneDX JSON files to merge, in order.
+	InputPaths []string
+	// OutputPath is where the merged CycloneDX JSON SBOM must be written.
+	OutputPath string
+	// WorkDir is a scratch directory that holds the inputs.
+	WorkDir string
+	// MergeMode is the requested merge structure.
+	MergeMode CycloneDxMergeMode
+	// Policy and Conflicts are used by mergers that support conflict-aware merging.
+	Policy    *MergePolicy
+	Conflicts *ConflictReport
+}
+
+// Merger merges several SBOMs into one. Implementations can be registered with RegisterMerger
+// and are then selectable by name through the --merge-tool flag.
+type Merger interface {
+	Merge(req MergeRequest) error
+}
+
+// MergerFactory creates a Merger for a converter, e.g. to look up configured CLI paths.
+type MergerFactory func(c *CLIConverter) (Merger, error)
+
+// externalMergerPrefix selects an ExternalCommandMerger directly by command, e.g. "exec:./my-merger --flag".
+const externalMergerPrefix = "exec:"
+
+var (
+	mergersMu sync.RWMutex
+	mergers   = make(map[string]MergerFactory)
+)
+
+func init() {
+	RegisterMerger("native", func(c *CLIConverter) (Merger, error) {
+		return NativeMerger{Version: c.toolVersion()}, nil
+	})
+	RegisterMerger("cyclonedx-cli", func(c *CLIConverter) (Merger, error) {
+		if c.CycloneDXCLIPath == "" {
+			return nil, fmt.Errorf("CycloneDX CLI path not set or found")
+		}
+		return &CycloneDXCLIMerger{CLIPath: c.CycloneDXCLIPath, converter: c}, nil
+	})
+}
+
+// RegisterMerger makes a merger available under the given name. Registering a name twice
+// replaces the previous factory.
+func RegisterMerger(name string, factory MergerFactory) {
+	mergersMu.Lock()
+	defer mergersMu.Unlock()
+	mergers[strings.ToLower(name)] = factory
+}
+
+// RegisteredMergers returns the names of all registered mergers, sorted.
+func RegisteredMergers() []string {
+	mergersMu.RLock()
+	defer mergersMu.RUnlock()
+	names := make([]string, 0, len(mergers))
+	for name := range mergers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupMerger returns the merger registered under name. Names starting with "exec:" create an
+// ExternalCommandMerger for the command that follows the prefix, split into arguments with
+// shell-style quoting.
+func LookupMerger(name string, c *CLIConverter) (Merger, error) {
+	if command, ok := strings.CutPrefix(name, externalMergerPrefix); ok {
+		args, err := splitCommandLine(command)
+		if err != nil {
+			return nil, fmt.Errorf("invalid command for external merger %q: %w", name, err)
+		}
+		if len(args) == 0 {
+			return nil, fmt.Errorf("no command given for external merger %q", name)
+		}
+		return NewExternalCommandMerger(args[0], args[1:]...), nil
+	}
+
+	mergersMu.RLock()
+	factory, ok := mergers[strings.ToLower(name)]
+	mergersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported merge tool: %s, choose one of %s or '%s<command>'",
+			name, strings.Join(RegisteredMergers(), ", "), externalMergerPrefix)
+	}
+	return factory(c)
+}
+
 // ComponentSbomMerger handles the merging of multiple SBOM files.
 type ComponentSbomMerger struct {
 	cliConverter *CLIConverter
@@ -34,107 +131,135 @@ func (m *ComponentSbomMerger) ComponentSbomMerge(componentResourceSbomDir string
 		return "", fmt.Errorf("no SBOMs provided to merge")
 	}
 
+	merger, err := LookupMerger(mergeTool, m.cliConverter)
+	if err != nil {
+		return "", err
+	}
+
 	// Save merged SBOM in the same directory as individual SBOMs with a descriptive name
 	safeComponentName := sanitizeFilename(componentName)
 	mergedSbomPath := filepath.Join(componentResourceSbomDir, fmt.Sprintf("merged-component-%s.json", safeComponentName))
-	var mergeErr error
-
-	switch strings.ToLower(mergeTool) {
-	case "native":
-		log.Println("Executing native Go merge...")
-		var boms []cyclonedx.BOM
-
-		// Read and decode each input SBOM file
-		for _, path := range resourceSbomPaths {
-			file, err := os.Open(path)
-			if err != nil {
-				return "", fmt.Errorf("failed to open sbom file %s: %w", path, err)
-			}
-
-			bom := cyclonedx.BOM{}
-			decoder := cyclonedx.NewBOMDecoder(file, cyclonedx.BOMFileFormatJSON)
-			if err = decoder.Decode(&bom); err != nil && err != io.EOF {
-				file.Close()
-				return "", fmt.Errorf("failed to decode sbom file %s: %w", path, err)
-			}
-			file.Close()
 
-			// Validate BOM has required metadata
-			if bom.Metadata == nil || bom.Metadata.Component == nil {
-				return "", fmt.Errorf("invalid BOM in file %s: missing metadata component", path)
-			}
+	req := MergeRequest{
+		ComponentName:    componentName,
+		ComponentVersion: componentVersion,
+		SubjectBOMRef:    m.cliConverter.componentBOMRef(componentName, componentVersion),
+		InputPaths:       resourceSbomPaths,
+		OutputPath:       mergedSbomPath,
+		WorkDir:          componentResourceSbomDir,
+		MergeMode:        m.cliConverter.mergeMode(),
+		Policy:           m.cliConverter.MergePolicy,
+		Conflicts:        &m.cliConverter.conflicts,
+	}
+	if err := merger.Merge(req); err != nil {
+		return "", fmt.Errorf("error merging SBOMs with %s: %w", mergeTool, err)
+	}
 
-			boms = append(boms, bom)
-		}
+	log.Printf("SBOMs successfully merged to: %s", mergedSbomPath)
+	return mergedSbomPath, nil
+}
 
-		// Prepare options and call the native merge function
-		opts := CycloneDxMergeOptions{
-			BOMs:      boms,
-			Name:      componentName,
-			Version:   componentVersion,
-			Group:     "",
-			MergeMode: MergeModeHierarchical,
-		}
+// NativeMerger merges SBOMs in-process with CycloneDXMerge.
+type NativeMerger struct {
+	// Version is the ocm-sbom version reported in metadata.tools.
+	Version string
+}
+
+// Merge implements Merger.
+func (NativeMerger) Merge(req MergeRequest) error {
+	log.Println("Executing native Go merge...")
+	var boms []cyclonedx.BOM
 
-		mergedBom, err := CycloneDXMerge(opts)
+	// Read and decode each input SBOM file
+	for _, path := range req.InputPaths {
+		file, err := os.Open(path)
 		if err != nil {
-			return "", fmt.Errorf("native merge failed: %w", err)
+			return fmt.Errorf("failed to open sbom file %s: %w", path, err)
 		}
 
-		// Set proper BOM specification version and format
-		mergedBom.BOMFormat = "CycloneDX"
-		mergedBom.SpecVersion = cyclonedx.SpecVersion1_6
-
-		// Validate merged BOM
-		if mergedBom.Metadata == nil || mergedBom.Metadata.Component == nil {
-			return "", fmt.Errorf("merged BOM is invalid: missing metadata component")
+		bom := cyclonedx.BOM{}
+		decoder := cyclonedx.NewBOMDecoder(file, cyclonedx.BOMFileFormatJSON)
+		if err = decoder.Decode(&bom); err != nil && err != io.EOF {
+			file.Close()
+			return fmt.Errorf("failed to decode sbom file %s: %w", path, err)
 		}
+		file.Close()
 
-		// Create output file and encode using CycloneDX encoder
-		outputFile, err := os.Create(mergedSbomPath)
-		if err != nil {
-			return "", fmt.Errorf("failed to create merged sbom file: %w", err)
+		// Validate BOM has required metadata
+		if bom.Metadata == nil || bom.Metadata.Component == nil {
+			return fmt.Errorf("invalid BOM in file %s: missing metadata component", path)
 		}
 
-		encoder := cyclonedx.NewBOMEncoder(outputFile, cyclonedx.BOMFileFormatJSON)
-		encoder.SetPretty(true)
-		mergeErr = encoder.Encode(mergedBom)
+		boms = append(boms, bom)
+	}
 
-		// Close and sync file
-		if closeErr := outputFile.Close(); closeErr != nil {
-			return "", fmt.Errorf("failed to close output file: %w", closeErr)
-		}
+	// Prepare options and call the native merge function
+	opts := CycloneDxMergeOptions{
+		BOMs:      boms,
+		Name:      req.ComponentName,
+		Version:   req.ComponentVersion,
+		Group:     "",
+		MergeMode: req.MergeMode,
+		BOMRef:    req.SubjectBOMRef,
+		Policy:    req.Policy,
+		Conflicts: req.Conflicts,
+	}
 
-		if mergeErr != nil {
-			return "", fmt.Errorf("failed to encode merged BOM: %w", mergeErr)
-		}
-	case "hoppr":
-		panic("hoppr merge not implemented yet")
-		if m.cliConverter.HopprCLIPath == "" {
-			return "", fmt.Errorf("hoppr (hopctl) CLI path not set or found")
-		}
-		mergeArgs := []string{"merge", "--sbom-dir", componentResourceSbomDir, "--output-file", mergedSbomPath, "--deep-merge"}
-		log.Printf("Executing Hoppr merge: %s %s", m.cliConverter.HopprCLIPath, strings.Join(mergeArgs, " "))
-		_, mergeErr = m.cliConverter.runCommand(m.cliConverter.HopprCLIPath, mergeArgs...)
-	case "cyclonedx-cli":
-		if m.cliConverter.CycloneDXCLIPath == "" {
-			return "", fmt.Errorf("CycloneDX CLI path not set or found")
-		}
-		// Build merge command: cyclonedx merge --input-files file1 file2 file3 --output-format json --output-file whatever.json
-		mergeArgs := []string{"merge", "--input-files"}
-		mergeArgs = append(mergeArgs, resourceSbomPaths...)
-		mergeArgs = append(mergeArgs, "--output-format", "json", "--output-file", mergedSbomPath)
-		mergeArgs = append(mergeArgs, "--hierarchical", "--name", componentName, "--version", componentVersion)
-		log.Printf("Executing CycloneDX CLI merge: %s %s", m.cliConverter.CycloneDXCLIPath, strings.Join(mergeArgs, " "))
-		_, mergeErr = m.cliConverter.runCommand(m.cliConverter.CycloneDXCLIPath, mergeArgs...)
-	default:
-		return "", fmt.Errorf("unsupported merge tool: %s, choose 'hoppr' or 'cyclonedx-cli'", mergeTool)
+	mergedBom, err := CycloneDXMerge(opts)
+	if err != nil {
+		return fmt.Errorf("native merge failed: %w", err)
 	}
 
-	if mergeErr != nil {
-		return "", fmt.Errorf("error merging SBOMs with %s: %w", mergeTool, mergeErr)
+	// Set proper BOM specification version and format
+	mergedBom.BOMFormat = "CycloneDX"
+	mergedBom.SpecVersion = cyclonedx.SpecVersion1_6
+
+	// Validate merged BOM
+	if mergedBom.Metadata == nil || mergedBom.Metadata.Component == nil {
+		return fmt.Errorf("merged BOM is invalid: missing metadata component")
 	}
 
-	log.Printf("SBOMs successfully merged to: %s", mergedSbomPath)
-	return mergedSbomPath, nil
+	return writeMergedBOM(mergedBom, req.OutputPath)
+}
+
+// writeMergedBOM encodes a merged BOM as pretty printed CycloneDX JSON.
+func writeMergedBOM(bom *cyclonedx.BOM, path string) error {
+	outputFile, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create merged sbom file: %w", err)
+	}
+
+	encoder := cyclonedx.NewBOMEncoder(outputFile, cyclonedx.BOMFileFormatJSON)
+	encoder.SetPretty(true)
+	encodeErr := encoder.Encode(bom)
+
+	// Close and sync file
+	if closeErr := outputFile.Close(); closeErr != nil {
+		return fmt.Errorf("failed to close output file: %w", closeErr)
+	}
+	if encodeErr != nil {
+		return fmt.Errorf("failed to encode merged BOM: %w", encodeErr)
+	}
+	return nil
+}
+
+// CycloneDXCLIMerger merges SBOMs with the external CycloneDX CLI ("cyclonedx merge").
+type CycloneDXCLIMerger struct {
+	CLIPath   string
+	converter *CLIConverter
+}
+
+// Merge implements Merger.
+func (m *CycloneDXCLIMerger) Merge(req MergeRequest) error {
+	// Build merge command: cyclonedx merge --input-files file1 file2 file3 --output-format json --output-file whatever.json
+	mergeArgs := []string{"merge", "--input-files"}
+	mergeArgs = append(mergeArgs, req.InputPaths...)
+	mergeArgs = append(mergeArgs, "--output-format", "json", "--output-file", req.OutputPath)
+	if req.MergeMode == MergeModeHierarchical {
+		mergeArgs = append(mergeArgs, "--hierarchical")
+	}
+	mergeArgs = append(mergeArgs, "--name", req.ComponentName, "--version", req.ComponentVersion)
+	log.Printf("Executing CycloneDX CLI merge: %s %s", m.CLIPath, strings.Join(mergeArgs, " "))
+	_, err := m.converter.runCommand(m.CLIPath, mergeArgs...)
+	return err
 }
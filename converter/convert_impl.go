@@ -7,41 +7,107 @@
 package converter
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/CycloneDX/cyclonedx-go"
+	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
 	"ocm.software/open-component-model/bindings/go/oci"
+	"sigs.k8s.io/yaml"
 )
 
 // ConvertOCMToSBOM orchestrates reading components from a CTF, generating SBOMs for their
 // resources via Syft, merging a per-component via CycloneDX CLI (default), and optionally
 // converting the final output format.
 func (c *CLIConverter) ConvertOCMToSBOM(cftPath string, componentName string, targetFormat SBOMFormat, mergeTool string) ([]byte, error) {
+	sboms, err := c.ConvertOCMToSBOMs(cftPath, componentName, []SBOMFormat{targetFormat}, mergeTool)
+	if err != nil {
+		return nil, err
+	}
+	return sboms[targetFormat], nil
+}
+
+// ConvertOCMToSBOMs scans and merges the component graph once and renders the merged SBOM into
+// every requested format. The result maps each format to the rendered document.
+func (c *CLIConverter) ConvertOCMToSBOMs(cftPath string, componentName string, formats []SBOMFormat, mergeTool string) (map[SBOMFormat][]byte, error) {
+	if len(formats) == 0 {
+		return nil, fmt.Errorf("no target format given")
+	}
+
+	c.report(Event{Type: EventConversionStarted, Component: componentName, Message: cftPath})
+	defer c.report(Event{Type: EventConversionFinished, Component: componentName})
+
+	c.conflicts = ConflictReport{}
+	c.failures = nil
+	c.signatures = nil
+	c.componentSBOMs = nil
+	c.resourceSBOMs = nil
+	c.ctfPath = cftPath
+	c.mergeTool = mergeTool
+	c.rootOCIRepository = ""
+
+	stopBusWatch := c.watchSyftBus()
+	defer stopBusWatch()
+
 	repo, err := createRepository(cftPath)
 	if err != nil {
 		return nil, fmt.Errorf("error creating repository: %w", err)
 	}
 
 	// Process all components recursively starting at the given component (use version 1.0.0 for now) TODO: support version selection
-	allComponentSBOMPaths, err := c.processAllComponents(repo, componentName, "1.0.0", targetFormat, mergeTool)
+	componentVersion := "1.0.0"
+
+	// Refuse to describe component versions that may have been tampered with
+	if c.SignatureVerifier != nil {
+		if err := c.verifyComponentGraph(repo, componentName, componentVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	// Resource SBOMs are always CycloneDX JSON; the target formats are rendered from the merged SBOM.
+	allComponentSBOMPaths, err := c.processAllComponents(repo, componentName, componentVersion, FormatCycloneDXJSON, mergeTool)
 	if err != nil {
 		return nil, fmt.Errorf("error processing components: %w", err)
 	}
+
+	sboms := make(map[SBOMFormat][]byte, len(formats))
 	if len(allComponentSBOMPaths) == 0 {
 		log.Println("No SBOMs were generated for any components. Result will be empty.")
-		return []byte{}, nil
+		for _, format := range formats {
+			sboms[format] = []byte{}
+		}
+		return sboms, nil
 	}
 
 	// The first entry is the fully merged SBOM for the root component (parent stays root).
 	rootComponentSbomPath := allComponentSBOMPaths[0]
 	log.Printf("Final merged SBOM at: %s", rootComponentSbomPath)
 
-	// Optionally convert to the desired output format
-	return c.convertFinalSBOM(rootComponentSbomPath, targetFormat)
+	bom, err := c.finalizeSBOM(rootComponentSbomPath, formats)
+	if err != nil {
+		return nil, fmt.Errorf("error finalizing SBOM: %w", err)
+	}
+
+	// Render the merged SBOM into every requested format
+	for _, format := range formats {
+		if _, done := sboms[format]; done {
+			continue
+		}
+		content, err := c.convertFinalSBOM(bom, rootComponentSbomPath, format)
+		if err != nil {
+			return nil, fmt.Errorf("error rendering SBOM as %s: %w", format, err)
+		}
+		sboms[format] = content
+	}
+	return sboms, nil
 }
 
 // processAllComponents traverses the component hierarchy, generates SBOMs for each component's
@@ -55,7 +121,7 @@ func (c *CLIConverter) processAllComponents(repo oci.ComponentVersionRepository,
 	merger := NewComponentSbomMerger(c)
 
 	// Graph and bookkeeping
-	type compKey struct{ Name, Version string }
+	type compKey struct{ Name, Version, Parent string }
 	id := func(n, v string) string { return fmt.Sprintf("%s:%s", n, v) }
 
 	rootID := id(componentName, componentVersion)
@@ -64,7 +130,7 @@ func (c *CLIConverter) processAllComponents(repo oci.ComponentVersionRepository,
 	queue := []compKey{{Name: componentName, Version: componentVersion}}
 
 	// Store descriptors, child relations, reverse parent relations, and per-node SBOM paths
-	descriptors := make(map[string]interface{})   // kept for future use; not needed for name/version
+	descriptors := make(map[string]*runtime.Descriptor)
 	childrenOf := make(map[string][]string)       // parentID -> []childID
 	parentsOf := make(map[string]map[string]void) // childID -> set(parentID)
 	resourceSBOMPath := make(map[string]string)   // nodeID -> path of SBOM for that node's resources
@@ -88,15 +154,34 @@ func (c *CLIConverter) processAllComponents(repo oci.ComponentVersionRepository,
 		runtimeDesc, err := repo.GetComponentVersion(ctx, curr.Name, curr.Version)
 		if err != nil {
 			log.Printf("Warning: could not get component version for %s: %v", currID, err)
+			c.report(Event{Type: EventComponentFailed, Component: currID, Error: err.Error()})
+			c.recordFailure(ComponentFailure{Kind: FailureDescriptor, Component: curr.Name, Version: curr.Version, Parent: curr.Parent, Reason: err.Error()})
 			// Skip if descriptor cannot be obtained
 			continue
 		}
+		c.report(Event{Type: EventComponentDiscovered, Component: currID})
+		c.recordSignatures(runtimeDesc)
+		if currID == rootID {
+			c.rootOCIRepository = ociRepository(runtimeDesc)
+		}
 
 		// Generate and store resource-only SBOM for this component
 		mergedSBOMPath, err := processor.ProcessComponent(runtimeDesc, outputFormat, mergeTool)
+		if errors.Is(err, ErrMergeConflict) {
+			return nil, err
+		}
 		if err != nil {
 			log.Printf("Warning: error processing component %s: %v", currID, err)
+			c.report(Event{Type: EventComponentFailed, Component: currID, Error: err.Error()})
+			c.recordFailure(ComponentFailure{Kind: FailureResource, Component: curr.Name, Version: curr.Version, Parent: curr.Parent, Reason: err.Error()})
 			// Keep going; if no SBOM, children might still produce results
+		} else {
+			c.report(Event{Type: EventComponentScanned, Component: currID})
+		}
+		for i := range c.failures {
+			if f := &c.failures[i]; f.Component == curr.Name && f.Version == curr.Version && f.Parent == "" {
+				f.Parent = curr.Parent
+			}
 		}
 		if mergedSBOMPath != "" {
 			resourceSBOMPath[currID] = mergedSBOMPath
@@ -117,7 +202,7 @@ func (c *CLIConverter) processAllComponents(repo oci.ComponentVersionRepository,
 			parentsOf[childID][currID] = struct{}{}
 
 			if !visited[childID] {
-				queue = append(queue, compKey{Name: ref.Component, Version: ref.Version})
+				queue = append(queue, compKey{Name: ref.Component, Version: ref.Version, Parent: currID})
 			}
 		}
 	}
@@ -127,9 +212,13 @@ func (c *CLIConverter) processAllComponents(repo oci.ComponentVersionRepository,
 		return nil, nil
 	}
 
-	// Initialize remaining children counts
+	// Initialize remaining children counts; children without a descriptor never report back
 	for nid := range allNodes {
-		remainingChildren[nid] = len(childrenOf[nid])
+		for _, cid := range childrenOf[nid] {
+			if _, ok := allNodes[cid]; ok {
+				remainingChildren[nid]++
+			}
+		}
 	}
 
 	// 2) Bottom-up merging: process leaves first, propagate upwards
@@ -140,6 +229,8 @@ func (c *CLIConverter) processAllComponents(repo oci.ComponentVersionRepository,
 			batch = append(batch, nid)
 		}
 	}
+	// Map iteration order is random; keep the merge order stable between runs
+	sort.Strings(batch)
 
 	for len(processed) < len(allNodes) && len(batch) > 0 {
 		next := make([]string, 0)
@@ -184,12 +275,21 @@ func (c *CLIConverter) processAllComponents(repo oci.ComponentVersionRepository,
 				}
 
 				outPath, err := merger.ComponentSbomMerge(c.TempDir, files, mergeTool, compName, compVersion)
+				if errors.Is(err, ErrMergeConflict) {
+					return nil, err
+				}
 				if err != nil {
 					log.Printf("Warning: merge failed for %s, using first input: %v", nid, err)
+					c.report(Event{Type: EventComponentFailed, Component: nid, Error: err.Error()})
+					c.recordFailure(ComponentFailure{Kind: FailureMerge, Component: compName, Version: compVersion, Reason: err.Error()})
 				} else {
 					finalPath = outPath
+					if err := c.editComponentSBOM(finalPath, descriptors[nid]); err != nil {
+						log.Printf("Warning: could not add the metadata of %s: %v", nid, err)
+					}
 				}
 			}
+			c.report(Event{Type: EventComponentMerged, Component: nid})
 
 			resultPath[nid] = finalPath
 			processed[nid] = true
@@ -202,6 +302,7 @@ func (c *CLIConverter) processAllComponents(repo oci.ComponentVersionRepository,
 				}
 			}
 		}
+		sort.Strings(next)
 		batch = next
 	}
 
@@ -219,6 +320,29 @@ func (c *CLIConverter) processAllComponents(repo oci.ComponentVersionRepository,
 		return nil, nil
 	}
 
+	// Remember the SBOM of every component version for finalizing and attaching, the root first
+	subject := func(nid string) componentSBOM {
+		sbom := componentSBOM{Path: resultPath[nid]}
+		sbom.Name, sbom.Version, _ = strings.Cut(nid, ":")
+		if descriptor := descriptors[nid]; descriptor != nil {
+			sbom.CreationTime = descriptorCreationTime(descriptor)
+			sbom.Supplier = ProviderSupplier(descriptor, c.SupplierConfig)
+		}
+		return sbom
+	}
+	c.componentSBOMs = []componentSBOM{subject(rootID)}
+	c.componentSBOMs[0].Path = rootMerged
+	children := make([]string, 0, len(resultPath))
+	for nid := range resultPath {
+		if nid != rootID {
+			children = append(children, nid)
+		}
+	}
+	sort.Strings(children)
+	for _, nid := range children {
+		c.componentSBOMs = append(c.componentSBOMs, subject(nid))
+	}
+
 	// Keep return type the same; ensure the root SBOM is first.
 	return []string{rootMerged}, nil
 }
@@ -273,43 +397,171 @@ func (c *CLIConverter) processAllComponentsOld(repo oci.ComponentVersionReposito
 	return allComponentSBOMPaths, nil
 }
 
-// convertFinalSBOM converts the final SBOM into the target format when required.
-func (c *CLIConverter) convertFinalSBOM(sourceSBOMPath string, targetFormat SBOMFormat) ([]byte, error) {
-	// If CycloneDX JSON is desired, we can just read the merged file directly.
-	if strings.ToLower(string(targetFormat)) == "cyclonedx-json" {
+// finalizeSBOM applies the document-wide post-processing steps to the merged root SBOM in place
+// and returns the result.
+func (c *CLIConverter) finalizeSBOM(sbomPath string, formats []SBOMFormat) (*cyclonedx.BOM, error) {
+	processor := NewCycloneDXProcessor()
+	bom, err := processor.Parse(sbomPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.finalizeBOM(bom, c.componentSBOMs[0], c.failures, &c.conflicts, formats); err != nil {
+		return nil, err
+	}
+	if err := processor.Write(bom, sbomPath, 0); err != nil {
+		return nil, err
+	}
+	return bom, nil
+}
+
+// renderSBOM finalizes the SBOM of a referenced component version or of a resource of subject
+// like the root SBOM and renders it in every format. The merged input is left untouched; only
+// the failures concerning component versions described by the SBOM are recorded.
+func (c *CLIConverter) renderSBOM(subject componentSBOM, sbomPath string, formats []SBOMFormat) (map[SBOMFormat][]byte, error) {
+	processor := NewCycloneDXProcessor()
+	bom, err := processor.Parse(sbomPath)
+	if err != nil {
+		return nil, err
+	}
+	// Conflicts within the SBOM are part of the root SBOM and already reported
+	if err := c.finalizeBOM(bom, subject, failuresWithin(bom, c.failures), &ConflictReport{}, formats); err != nil {
+		return nil, err
+	}
+	finalPath := strings.TrimSuffix(sbomPath, filepath.Ext(sbomPath)) + ".final.json"
+	if err := processor.Write(bom, finalPath, 0); err != nil {
+		return nil, err
+	}
+
+	sboms := make(map[SBOMFormat][]byte, len(formats))
+	for _, format := range formats {
+		content, err := c.convertFinalSBOM(bom, finalPath, format)
+		if err != nil {
+			return nil, fmt.Errorf("error rendering SBOM as %s: %w", format, err)
+		}
+		sboms[format] = content
+	}
+	return sboms, nil
+}
+
+// creationTimes maps "name@version" of every component version to the creation time of its descriptor.
+func (c *CLIConverter) creationTimes() map[string]time.Time {
+	created := make(map[string]time.Time)
+	for _, sbom := range c.componentSBOMs {
+		if ts, err := time.Parse(time.RFC3339, sbom.CreationTime); err == nil {
+			created[sbom.Name+"@"+sbom.Version] = ts
+		}
+	}
+	return created
+}
+
+// finalizeBOM applies the document-wide post-processing steps to an SBOM of subject, recording
+// the given failures and the metadata conflicts of deduplication in conflicts.
+func (c *CLIConverter) finalizeBOM(bom *cyclonedx.BOM, subject componentSBOM, failures []ComponentFailure, conflicts *ConflictReport, formats []SBOMFormat) error {
+	if c.Deduplicate {
+		removed, err := DeduplicateComponentsWithTimestamps(bom, c.MergePolicy, conflicts, c.creationTimes())
+		if err != nil {
+			return fmt.Errorf("deduplication failed: %w", err)
+		}
+		log.Printf("Deduplicated packages by purl/hash: %d duplicate components removed", removed)
+	}
+
+	// NTIA minimum elements: the supplier of the subject component supplies the SBOM
+	if subject.Supplier != nil && bom.Metadata != nil {
+		bom.Metadata.Supplier = subject.Supplier
+	}
+
+	// Provenance: which component versions were signed, and by whom
+	AddSignatureDeclarations(bom, c.signatures)
+
+	// Make failed lookups and scans visible instead of looking like components without packages
+	AnnotateFailures(bom, failures)
+
+	// Record the toolchain so the SBOM can be traced to the exact tools and settings
+	setToolComponents(bom, c.toolComponents(c.mergeTool, formats))
+
+	if c.Reproducible {
+		timestamp, err := ReproducibleTimestamp(subject.CreationTime)
+		if err != nil {
+			return err
+		}
+		if err := MakeReproducible(bom, timestamp); err != nil {
+			return err
+		}
+		log.Printf("Reproducible SBOM: timestamp %s, serial number %s", bom.Metadata.Timestamp, bom.SerialNumber)
+	}
+	return nil
+}
+
+// convertFinalSBOM renders the finalized SBOM, also stored at sourceSBOMPath, in the target format.
+func (c *CLIConverter) convertFinalSBOM(bom *cyclonedx.BOM, sourceSBOMPath string, targetFormat SBOMFormat) ([]byte, error) {
+	// If CycloneDX JSON in the merge version is desired, we can just read the merged file directly.
+	if strings.ToLower(string(targetFormat)) == "cyclonedx-json" && c.specVersion() == DefaultSpecVersion {
 		log.Println("No format conversion needed; reading merged SBOM directly")
 		return os.ReadFile(sourceSBOMPath)
 	}
 
-	if c.CycloneDXCLIPath == "" {
-		return nil, fmt.Errorf("CycloneDX CLI path not set or found, but required for conversion")
+	// CycloneDX and SPDX are rendered in process; SPDX keeps the component hierarchy as relationships.
+	switch targetFormat {
+	case FormatCycloneDXJSON:
+		return c.convertToCycloneDX(bom, cyclonedx.BOMFileFormatJSON)
+	case FormatCycloneDXXML:
+		return c.convertToCycloneDX(bom, cyclonedx.BOMFileFormatXML)
+	case FormatCycloneDXYAML:
+		return c.convertToCycloneDXYAML(bom)
+	case FormatSPDXJSON, FormatSPDXYAML, FormatSPDXTagValue:
+		return c.convertToSPDX23(bom, targetFormat)
+	case FormatSPDX3JSON:
+		return c.convertToSPDX3(bom)
 	}
 
-	convertedSBOMPath := filepath.Join(c.TempDir, "final_converted_sbom.json")
-	outputFormatArg := string(targetFormat)
-	outputVersion := "1.6" // default for CycloneDX
+	return nil, fmt.Errorf("unsupported output format: %s", targetFormat)
+}
 
-	if strings.HasSuffix(outputFormatArg, "-yaml") {
-		convertedSBOMPath = filepath.Join(c.TempDir, "final_converted_sbom.yaml")
-		outputFormatArg = "yaml"
-	} else if strings.HasSuffix(outputFormatArg, "-json") {
-		outputFormatArg = "json"
+// convertToCycloneDX renders the merged CycloneDX SBOM in the configured spec version.
+func (c *CLIConverter) convertToCycloneDX(bom *cyclonedx.BOM, format cyclonedx.BOMFileFormat) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := EncodeCycloneDX(bom, &buf, format, c.specVersion()); err != nil {
+		return nil, fmt.Errorf("failed to write SBOM as CycloneDX %s: %w", c.specVersion(), err)
 	}
+	return buf.Bytes(), nil
+}
 
-	if strings.HasPrefix(string(targetFormat), "spdx") {
-		outputVersion = "2.3" // default for SPDX
+// convertToCycloneDXYAML renders the merged CycloneDX SBOM as YAML, the JSON encoding with the
+// same field names.
+func (c *CLIConverter) convertToCycloneDXYAML(bom *cyclonedx.BOM) ([]byte, error) {
+	data, err := c.convertToCycloneDX(bom, cyclonedx.BOMFileFormatJSON)
+	if err != nil {
+		return nil, err
+	}
+	out, err := yaml.JSONToYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write SBOM as CycloneDX YAML: %w", err)
+	}
+	return out, nil
+}
+
+// convertToSPDX23 renders the merged CycloneDX SBOM as an SPDX 2.3 document.
+func (c *CLIConverter) convertToSPDX23(bom *cyclonedx.BOM, targetFormat SBOMFormat) ([]byte, error) {
+	doc, err := CycloneDXToSPDX23(bom)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map SBOM to SPDX 2.3: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteSPDX23(doc, &buf, targetFormat); err != nil {
+		return nil, fmt.Errorf("failed to write SBOM as %s: %w", targetFormat, err)
 	}
+	return buf.Bytes(), nil
+}
 
-	args := []string{
-		"convert",
-		"--input-file", sourceSBOMPath,
-		"--output-file", convertedSBOMPath,
-		"--output-format", outputFormatArg,
-		"--output-version", outputVersion,
+// convertToSPDX3 renders the merged CycloneDX SBOM as an SPDX 3 JSON-LD document.
+func (c *CLIConverter) convertToSPDX3(bom *cyclonedx.BOM) ([]byte, error) {
+	doc, err := CycloneDXToSPDX3(bom)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map SBOM to SPDX 3: %w", err)
 	}
-	log.Printf("Running CycloneDX CLI convert: %s %s", c.CycloneDXCLIPath, strings.Join(args, " "))
-	if _, err := c.runCommand(c.CycloneDXCLIPath, args...); err != nil {
-		return nil, fmt.Errorf("failed to convert SBOM to %s: %w", targetFormat, err)
+	var buf bytes.Buffer
+	if err := WriteSPDX3(doc, &buf); err != nil {
+		return nil, fmt.Errorf("failed to write SBOM as %s: %w", FormatSPDX3JSON, err)
 	}
-	return os.ReadFile(convertedSBOMPath)
+	return buf.Bytes(), nil
 }
@@ -0,0 +1,121 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"sigs.k8s.io/yaml"
+)
+
+func TestConvertFinalSBOMRendersCycloneDXYAML(t *testing.T) {
+	bom := cyclonedx.NewBOM()
+	bom.Metadata = &cyclonedx.Metadata{Component: &cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/app", Version: "1.0.0", BOMRef: "app"}}
+	bom.Components = &[]cyclonedx.Component{{Type: cyclonedx.ComponentTypeLibrary, Name: "zlib", Version: "1.3", BOMRef: "zlib"}}
+
+	// No CycloneDX CLI is configured, YAML must be rendered in process
+	c := &CLIConverter{TempDir: t.TempDir()}
+	out, err := c.convertFinalSBOM(bom, "", FormatCycloneDXYAML)
+	if err != nil {
+		t.Fatalf("convertFinalSBOM: %v", err)
+	}
+
+	data, err := yaml.YAMLToJSON(out)
+	if err != nil {
+		t.Fatalf("output is not YAML: %v\n%s", err, out)
+	}
+	var decoded cyclonedx.BOM
+	if err := cyclonedx.NewBOMDecoder(bytes.NewReader(data), cyclonedx.BOMFileFormatJSON).Decode(&decoded); err != nil {
+		t.Fatalf("output is not a CycloneDX SBOM: %v", err)
+	}
+	if decoded.BOMFormat != cyclonedx.BOMFormat || decoded.Metadata == nil || decoded.Metadata.Component.Name != "acme.org/app" {
+		t.Errorf("decoded SBOM = %+v", decoded)
+	}
+	if decoded.Components == nil || len(*decoded.Components) != 1 || (*decoded.Components)[0].BOMRef != "zlib" {
+		t.Errorf("components = %+v, want zlib", decoded.Components)
+	}
+
+	for _, tool := range c.toolComponents("native", []SBOMFormat{FormatCycloneDXYAML}) {
+		if tool.Name == "cyclonedx-cli" {
+			t.Errorf("toolchain records cyclonedx-cli although YAML is rendered in process")
+		}
+	}
+}
+
+func TestRenderSBOMFinalizesChildSBOM(t *testing.T) {
+	zlib := func(ref string) cyclonedx.Component {
+		return cyclonedx.Component{Type: cyclonedx.ComponentTypeLibrary, Name: "zlib", Version: "1.3", PackageURL: "pkg:apk/alpine/zlib@1.3", BOMRef: ref}
+	}
+	lib := cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/lib", Version: "1.0.0", BOMRef: "lib"}
+	child := cyclonedx.NewBOM()
+	child.SerialNumber = "urn:uuid:8c1d1f5e-4a3b-4e0f-9b7f-0e6f3d1b2a11"
+	child.Metadata = &cyclonedx.Metadata{Component: &lib}
+	child.Components = &[]cyclonedx.Component{
+		{Type: cyclonedx.ComponentTypeContainer, Name: "image-a", BOMRef: "image-a", Components: &[]cyclonedx.Component{zlib("zlib-a")}},
+		{Type: cyclonedx.ComponentTypeContainer, Name: "image-b", BOMRef: "image-b", Components: &[]cyclonedx.Component{zlib("zlib-b")}},
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "lib.json")
+	processor := NewCycloneDXProcessor()
+	if err := processor.Write(child, path, 0); err != nil {
+		t.Fatal(err)
+	}
+	merged, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &CLIConverter{TempDir: dir, Deduplicate: true, Reproducible: true, mergeTool: "native"}
+	c.failures = []ComponentFailure{
+		{Kind: FailureResource, Component: "acme.org/lib", Version: "1.0.0", Resource: "chart", Reason: "unsupported"},
+		// A sibling of lib under the root, not part of its SBOM
+		{Kind: FailureDescriptor, Component: "acme.org/other", Version: "2.0.0", Parent: "acme.org/app:1.0.0", Reason: "not found"},
+	}
+	subject := componentSBOM{Name: "acme.org/lib", Version: "1.0.0", Path: path, CreationTime: "2025-03-01T12:00:00Z", Supplier: &cyclonedx.OrganizationalEntity{Name: "ACME"}}
+
+	rendered, err := c.renderSBOM(subject, path, []SBOMFormat{FormatCycloneDXJSON, FormatSPDXJSON})
+	if err != nil {
+		t.Fatalf("renderSBOM: %v", err)
+	}
+	if len(rendered[FormatSPDXJSON]) == 0 {
+		t.Errorf("no SPDX rendering")
+	}
+	var bom cyclonedx.BOM
+	if err := cyclonedx.NewBOMDecoder(bytes.NewReader(rendered[FormatCycloneDXJSON]), cyclonedx.BOMFileFormatJSON).Decode(&bom); err != nil {
+		t.Fatalf("rendered SBOM is not CycloneDX: %v", err)
+	}
+
+	if refs := componentRefs(&bom); slices.Contains(refs, "zlib-a") == slices.Contains(refs, "zlib-b") {
+		t.Errorf("component refs = %v, want zlib deduplicated", refs)
+	}
+	if bom.Metadata.Supplier == nil || bom.Metadata.Supplier.Name != "ACME" {
+		t.Errorf("supplier = %+v, want ACME", bom.Metadata.Supplier)
+	}
+	if bom.Metadata.Timestamp != "2025-03-01T12:00:00Z" || bom.SerialNumber == child.SerialNumber {
+		t.Errorf("timestamp %q, serial %q: want the reproducible values of lib", bom.Metadata.Timestamp, bom.SerialNumber)
+	}
+	if bom.Metadata.Tools == nil || bom.Metadata.Tools.Components == nil || !slices.ContainsFunc(*bom.Metadata.Tools.Components, func(tool cyclonedx.Component) bool { return tool.Name == ToolName }) {
+		t.Errorf("tools = %+v, want the ocm-sbom toolchain", bom.Metadata.Tools)
+	}
+	if bom.Compositions == nil || len(*bom.Compositions) != 1 || (*(*bom.Compositions)[0].Assemblies)[0] != "lib" {
+		t.Errorf("compositions = %+v, want lib incomplete only", bom.Compositions)
+	}
+	if slices.Contains(componentRefs(&bom), "acme.org/other@2.0.0") {
+		t.Errorf("placeholder for a component outside of the SBOM was added")
+	}
+
+	// The merged SBOM is an input of the parent merge and must stay as it is
+	if after, _ := os.ReadFile(path); !bytes.Equal(after, merged) {
+		t.Errorf("merged SBOM was modified")
+	}
+}
@@ -12,8 +12,11 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
 )
 
 // SBOMFormat represents the format of the SBOM to be generated.
@@ -23,12 +26,55 @@ const (
 	FormatCycloneDXJSON SBOMFormat = "cyclonedx-json"
 	FormatSPDXJSON      SBOMFormat = "spdx-json"
 	FormatCycloneDXYAML SBOMFormat = "cyclonedx-yaml"
+	FormatCycloneDXXML  SBOMFormat = "cyclonedx-xml"
 	FormatSPDXYAML      SBOMFormat = "spdx-yaml"
+	FormatSPDXTagValue  SBOMFormat = "spdx-tag-value"
+	FormatSPDX3JSON     SBOMFormat = "spdx3-json"
 )
 
+// Extension returns the file extension conventionally used for the format.
+func (f SBOMFormat) Extension() string {
+	switch f {
+	case FormatCycloneDXJSON:
+		return ".cdx.json"
+	case FormatCycloneDXYAML:
+		return ".cdx.yaml"
+	case FormatCycloneDXXML:
+		return ".cdx.xml"
+	case FormatSPDXJSON:
+		return ".spdx.json"
+	case FormatSPDXYAML:
+		return ".spdx.yaml"
+	case FormatSPDXTagValue:
+		return ".spdx"
+	case FormatSPDX3JSON:
+		return ".spdx3.json"
+	default:
+		return ""
+	}
+}
+
+// sbomFormats lists all formats, for lookups by extension.
+var sbomFormats = []SBOMFormat{FormatCycloneDXJSON, FormatSPDXJSON, FormatCycloneDXYAML, FormatCycloneDXXML, FormatSPDXYAML, FormatSPDXTagValue, FormatSPDX3JSON}
+
+// OutputPathForFormat returns the path the SBOM in format is written to if several formats are
+// requested: path with its SBOM extension, e.g. ".cdx.json", or else its plain extension replaced
+// by the extension of format.
+func OutputPathForFormat(path string, format SBOMFormat) string {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	longest := 0
+	for _, f := range sbomFormats {
+		if ext := f.Extension(); len(ext) > longest && strings.HasSuffix(path, ext) {
+			base, longest = strings.TrimSuffix(path, ext), len(ext)
+		}
+	}
+	return base + format.Extension()
+}
+
 // SBOMConverter defines the interface for converting OCM component descriptors to SBOM formats.
 type SBOMConverter interface {
-	ConvertOCMToSBOM(ocmDescriptorPath string, targetFormat SBOMFormat, mergeTool string, convertTool string) ([]byte, error)
+	ConvertOCMToSBOM(ctfPath string, componentName string, targetFormat SBOMFormat, mergeTool string) ([]byte, error)
+	ConvertOCMToSBOMs(ctfPath string, componentName string, formats []SBOMFormat, mergeTool string) (map[SBOMFormat][]byte, error)
 }
 
 // CLIConverter is the implementation of SBOMConverter that uses command-line tools to perform the conversion and merging of SBOMs.
@@ -38,6 +84,70 @@ type CLIConverter struct {
 	ProtobomCLIPath  string
 	SyftCLIPath      string
 	TempDir          string
+
+	// ToolVersion is the ocm-sbom version recorded in metadata.tools; empty means "dev".
+	ToolVersion string
+
+	// SpecVersion is the CycloneDX version of the output; zero means DefaultSpecVersion. Older
+	// versions drop or convert the fields they do not support.
+	SpecVersion cyclonedx.SpecVersion
+
+	// MergeMode selects how SBOMs are combined; empty means MergeModeHierarchical.
+	MergeMode CycloneDxMergeMode
+
+	// BOMRefScheme selects how bom-refs are assigned; empty means BOMRefSchemeNamespaced.
+	BOMRefScheme BOMRefScheme
+
+	// Deduplicate collapses packages with the same purl (or hash) across images into one component.
+	Deduplicate bool
+
+	// MergePolicy resolves components that share a purl but differ in metadata during flat merges
+	// and deduplication; nil keeps the first component.
+	MergePolicy *MergePolicy
+
+	// Reproducible makes the output byte-identical across runs: fixed timestamps, canonical
+	// ordering and a serial number derived from the content.
+	Reproducible bool
+
+	// LabelFilter selects the OCM labels of components and resources exported as properties; nil
+	// exports all labels.
+	LabelFilter *LabelFilter
+
+	// SupplierConfig overrides the suppliers derived from OCM providers; nil uses the providers as
+	// they are.
+	SupplierConfig *SupplierConfig
+
+	// SignatureVerifier verifies the signatures of every component version of the graph before
+	// anything is scanned; nil skips verification.
+	SignatureVerifier *SignatureVerifier
+
+	// Progress receives pipeline events; nil disables progress reporting.
+	Progress ProgressReporter
+
+	// conflicts collects the metadata conflicts found by MergePolicy.
+	conflicts ConflictReport
+
+	// ctfPath is the CTF being converted, the location of component versions without repository
+	// context.
+	ctfPath string
+
+	// mergeTool is the merge tool of the last conversion, recorded in the toolchain of its SBOMs.
+	mergeTool string
+
+	// signatures collects the signatures of the component versions, recorded as declarations.
+	signatures []componentSignatures
+
+	// componentSBOMs are the merged SBOMs of the component versions, the root first.
+	componentSBOMs []componentSBOM
+
+	// resourceSBOMs are the SBOMs of the scanned resources, published as referrers of their images.
+	resourceSBOMs []resourceSBOM
+
+	// rootOCIRepository is the OCI repository of the root component version, if it has one.
+	rootOCIRepository string
+
+	// failures collects descriptor lookups, scans and merges that failed during the conversion.
+	failures []ComponentFailure
 }
 
 // NewCLIConverter creates a new instance of CLIConverter.
@@ -102,6 +212,19 @@ func NewCLIConverter(hopprPath, cyclonedxPath, protobomPath, syftPath, tempDir s
 	}, nil
 }
 
+// mergeMode returns the configured merge mode, defaulting to a hierarchical merge.
+func (c *CLIConverter) mergeMode() CycloneDxMergeMode {
+	if c.MergeMode == "" {
+		return MergeModeHierarchical
+	}
+	return c.MergeMode
+}
+
+// Conflicts returns the metadata conflicts found while merging with a MergePolicy.
+func (c *CLIConverter) Conflicts() *ConflictReport {
+	return &c.conflicts
+}
+
 // CleanupTempDir deletes the temporary directory used for intermediate files.
 func (c *CLIConverter) CleanupTempDir() error {
 	if c.TempDir != "" {
@@ -0,0 +1,31 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import "testing"
+
+func TestOutputPathForFormat(t *testing.T) {
+	tests := []struct {
+		path   string
+		format SBOMFormat
+		want   string
+	}{
+		{"sbom.json", FormatSPDXJSON, "sbom.spdx.json"},
+		{"sbom.cdx.json", FormatCycloneDXJSON, "sbom.cdx.json"},
+		{"sbom.cdx.json", FormatSPDXJSON, "sbom.spdx.json"},
+		{"out/sbom.spdx3.json", FormatCycloneDXXML, "out/sbom.cdx.xml"},
+		{"sbom.spdx.json", FormatSPDX3JSON, "sbom.spdx3.json"},
+		{"sbom.spdx", FormatCycloneDXYAML, "sbom.cdx.yaml"},
+		{"sbom", FormatSPDXTagValue, "sbom.spdx"},
+		{"release-1.2.json", FormatCycloneDXJSON, "release-1.2.cdx.json"},
+	}
+	for _, tt := range tests {
+		if got := OutputPathForFormat(tt.path, tt.format); got != tt.want {
+			t.Errorf("OutputPathForFormat(%q, %s) = %q, want %q", tt.path, tt.format, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,388 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+// occurrencePropertyPrefix namespaces the properties that record where a deduplicated package was found.
+const occurrencePropertyPrefix = "ocm-sbom:occurrence"
+
+// DeduplicateComponents collapses package components that describe the same package into a
+// single top level component. Packages are identified by their purl, or by their strongest hash
+// if no purl is set. Every parent that contained a duplicate gets a dependency on the remaining
+// component, and each occurrence (image and layer) is recorded as evidence and properties.
+// If a policy is given, differing metadata of the duplicates is resolved with it and recorded
+// in the report. It returns the number of components that were removed.
+func DeduplicateComponents(bom *cyclonedx.BOM, policy *MergePolicy, report *ConflictReport) (int, error) {
+	return DeduplicateComponentsWithTimestamps(bom, policy, report, nil)
+}
+
+// DeduplicateComponentsWithTimestamps deduplicates like DeduplicateComponents. created maps the
+// "name@version" of OCM component versions to the creation time of their descriptor; a package
+// found in a resource of a component version is as new as that component version, which is
+// what the prefer-newest strategy compares.
+func DeduplicateComponentsWithTimestamps(bom *cyclonedx.BOM, policy *MergePolicy, report *ConflictReport, created map[string]time.Time) (int, error) {
+	if bom == nil || bom.Components == nil {
+		return 0, nil
+	}
+
+	d := &dedupState{
+		canonical:   make(map[string]int),
+		refMap:      make(map[string]string),
+		occurrences: make(map[string]int),
+		sources:     make(map[int]mergeSource),
+		policy:      policy,
+		report:      report,
+		owners:      packageOwners(bom),
+		timestamps:  nodeTimestamps(bom, created),
+	}
+	kept, err := d.walk(*bom.Components, "")
+	if err != nil {
+		return 0, err
+	}
+	components := append(kept, d.hoisted...)
+	bom.Components = &components
+
+	// Rewrite all references to removed components
+	dependencies := []cyclonedx.Dependency{}
+	if bom.Dependencies != nil {
+		for _, dep := range *bom.Dependencies {
+			dep.Ref = d.mapRef(dep.Ref)
+			if dep.Dependencies != nil {
+				refs := make([]string, 0, len(*dep.Dependencies))
+				for _, ref := range *dep.Dependencies {
+					if ref = d.mapRef(ref); ref != dep.Ref {
+						refs = append(refs, ref)
+					}
+				}
+				dep.Dependencies = &refs
+			}
+			mergeDependency(&dependencies, dep)
+		}
+	}
+	for _, edge := range d.edges {
+		mergeDependency(&dependencies, cyclonedx.Dependency{Ref: d.mapRef(edge.Ref), Dependencies: edge.Dependencies})
+	}
+	if len(dependencies) > 0 {
+		bom.Dependencies = &dependencies
+	}
+
+	if bom.Compositions != nil {
+		for i := range *bom.Compositions {
+			composition := &(*bom.Compositions)[i]
+			for _, refs := range []*[]cyclonedx.BOMReference{composition.Assemblies, composition.Dependencies} {
+				if refs == nil {
+					continue
+				}
+				for j := range *refs {
+					(*refs)[j] = cyclonedx.BOMReference(d.mapRef(string((*refs)[j])))
+				}
+			}
+		}
+	}
+
+	if bom.Vulnerabilities != nil {
+		for i := range *bom.Vulnerabilities {
+			vulnerability := &(*bom.Vulnerabilities)[i]
+			if vulnerability.Affects == nil {
+				continue
+			}
+			affects := make([]cyclonedx.Affects, 0, len(*vulnerability.Affects))
+			for _, affect := range *vulnerability.Affects {
+				affect.Ref = d.mapRef(affect.Ref)
+				if !slices.ContainsFunc(affects, func(a cyclonedx.Affects) bool { return a.Ref == affect.Ref }) {
+					affects = append(affects, affect)
+				}
+			}
+			vulnerability.Affects = &affects
+		}
+	}
+
+	return d.removed, nil
+}
+
+// dedupState tracks the canonical component per package identity while walking a component tree.
+type dedupState struct {
+	canonical   map[string]int // package identity -> index in hoisted
+	hoisted     []cyclonedx.Component
+	refMap      map[string]string   // removed bom-ref -> canonical bom-ref
+	occurrences map[string]int      // canonical bom-ref -> number of recorded occurrences
+	sources     map[int]mergeSource // index in hoisted -> source of the canonical component
+	edges       []cyclonedx.Dependency
+	owners      map[string][]string  // bom-ref of a top level package -> bom-refs of the nodes that depend on it
+	timestamps  map[string]time.Time // bom-ref of a node -> creation time of its component version
+	removed     int
+	policy      *MergePolicy
+	report      *ConflictReport
+}
+
+// walk removes all package components from the list (recursively) and returns the remaining ones.
+// Packages are moved to the hoisted list, merged with earlier occurrences of the same package.
+func (d *dedupState) walk(components []cyclonedx.Component, parentRef string) ([]cyclonedx.Component, error) {
+	kept := make([]cyclonedx.Component, 0, len(components))
+	for _, comp := range components {
+		key := packageIdentity(comp)
+		if key == "" {
+			if comp.Components != nil {
+				nested, err := d.walk(*comp.Components, comp.BOMRef)
+				if err != nil {
+					return nil, err
+				}
+				comp.Components = &nested
+				if len(nested) == 0 {
+					comp.Components = nil
+				}
+			}
+			kept = append(kept, comp)
+			continue
+		}
+
+		nested := comp.Components
+		comp.Components = nil
+
+		// Flat merges don't nest packages, the nodes depending on them contain them
+		images := []string{parentRef}
+		if parentRef == "" && len(d.owners[comp.BOMRef]) > 0 {
+			images = d.owners[comp.BOMRef]
+		}
+
+		// Packages have no timestamp of their own; the containing node identifies the source.
+		source := mergeSource{Name: images[0], Timestamp: d.timestamps[images[0]]}
+		idx, seen := d.canonical[key]
+		if !seen {
+			idx = len(d.hoisted)
+			d.canonical[key] = idx
+			d.hoisted = append(d.hoisted, comp)
+			d.sources[idx] = source
+		} else {
+			d.removed++
+			if d.policy != nil {
+				if err := d.policy.apply(&d.hoisted[idx], d.sources[idx], comp, source, d.report); err != nil {
+					return nil, err
+				}
+				if source.Timestamp.After(d.sources[idx].Timestamp) {
+					d.sources[idx] = source
+				}
+			}
+		}
+
+		canonicalRef := d.hoisted[idx].BOMRef
+		if comp.BOMRef != "" && comp.BOMRef != canonicalRef {
+			d.refMap[comp.BOMRef] = canonicalRef
+		}
+		for _, image := range images {
+			d.addOccurrence(&d.hoisted[idx], comp, image)
+		}
+		if parentRef != "" && canonicalRef != "" {
+			d.edges = append(d.edges, cyclonedx.Dependency{Ref: parentRef, Dependencies: &[]string{canonicalRef}})
+		}
+
+		// Non-package children stay attached to the canonical component
+		if nested != nil {
+			children, err := d.walk(*nested, canonicalRef)
+			if err != nil {
+				return nil, err
+			}
+			if len(children) > 0 {
+				canon := &d.hoisted[idx]
+				if canon.Components == nil {
+					canon.Components = &[]cyclonedx.Component{}
+				}
+				*canon.Components = append(*canon.Components, children...)
+			}
+		}
+	}
+	return kept, nil
+}
+
+// addOccurrence records where an instance of the canonical component was found.
+func (d *dedupState) addOccurrence(canon *cyclonedx.Component, occurrence cyclonedx.Component, parentRef string) {
+	n := d.occurrences[canon.BOMRef]
+	d.occurrences[canon.BOMRef] = n + 1
+
+	prefix := fmt.Sprintf("%s:%d", occurrencePropertyPrefix, n)
+	var props []cyclonedx.Property
+	if parentRef != "" {
+		props = append(props, cyclonedx.Property{Name: prefix + ":image", Value: parentRef})
+	}
+
+	if canon.Evidence == nil {
+		canon.Evidence = &cyclonedx.Evidence{}
+	}
+	if canon.Evidence.Occurrences == nil {
+		canon.Evidence.Occurrences = &[]cyclonedx.EvidenceOccurrence{}
+	}
+	for _, loc := range syftLocations(occurrence) {
+		if loc.layerID != "" {
+			props = append(props, cyclonedx.Property{Name: prefix + ":layer", Value: loc.layerID})
+		}
+		if loc.path != "" && !slices.ContainsFunc(*canon.Evidence.Occurrences, func(o cyclonedx.EvidenceOccurrence) bool { return o.Location == loc.path }) {
+			*canon.Evidence.Occurrences = append(*canon.Evidence.Occurrences, cyclonedx.EvidenceOccurrence{Location: loc.path})
+		}
+	}
+	if len(*canon.Evidence.Occurrences) == 0 {
+		canon.Evidence.Occurrences = nil
+	}
+	if canon.Evidence.Identity == nil && canon.Evidence.Occurrences == nil && canon.Evidence.Callstack == nil && canon.Evidence.Licenses == nil && canon.Evidence.Copyright == nil {
+		canon.Evidence = nil
+	}
+
+	if len(props) > 0 {
+		if canon.Properties == nil {
+			canon.Properties = &[]cyclonedx.Property{}
+		}
+		*canon.Properties = append(*canon.Properties, props...)
+	}
+}
+
+// packageOwners maps the bom-refs of top level packages to the bom-refs of the non-package nodes
+// that depend on them, in the order of the dependency graph.
+func packageOwners(bom *cyclonedx.BOM) map[string][]string {
+	if bom.Dependencies == nil {
+		return nil
+	}
+	packages := make(map[string]bool)
+	for _, comp := range *bom.Components {
+		if comp.BOMRef != "" && packageIdentity(comp) != "" {
+			packages[comp.BOMRef] = true
+		}
+	}
+	owners := make(map[string][]string)
+	for _, dep := range *bom.Dependencies {
+		if dep.Dependencies == nil || packages[dep.Ref] {
+			continue
+		}
+		for _, ref := range *dep.Dependencies {
+			if packages[ref] && !slices.Contains(owners[ref], dep.Ref) {
+				owners[ref] = append(owners[ref], dep.Ref)
+			}
+		}
+	}
+	return owners
+}
+
+// nodeTimestamps maps the bom-refs of all component and resource nodes of the OCM tree to the
+// creation time of the component version they belong to.
+func nodeTimestamps(bom *cyclonedx.BOM, created map[string]time.Time) map[string]time.Time {
+	if len(created) == 0 {
+		return nil
+	}
+	root, err := BuildOCMTree(bom)
+	if err != nil {
+		return nil
+	}
+	timestamps := make(map[string]time.Time)
+	root.Walk(func(node *OCMNode) bool {
+		if node.Kind == OCMNodePackage {
+			return false
+		}
+		if ts, ok := created[node.OwningComponent().Label()]; ok && node.Ref() != "" {
+			timestamps[node.Ref()] = ts
+		}
+		return true
+	})
+	return timestamps
+}
+
+// mapRef returns the canonical bom-ref for a reference to a removed component.
+func (d *dedupState) mapRef(ref string) string {
+	if mapped, ok := d.refMap[ref]; ok {
+		return mapped
+	}
+	return ref
+}
+
+// packageIdentity returns the key used to identify a package across BOMs, or "" if the
+// component is not a deduplicatable package.
+func packageIdentity(comp cyclonedx.Component) string {
+	if comp.Type == cyclonedx.ComponentTypeContainer {
+		return ""
+	}
+	if comp.PackageURL != "" {
+		return "purl:" + comp.PackageURL
+	}
+	if comp.Hashes == nil || len(*comp.Hashes) == 0 {
+		return ""
+	}
+	hashes := slices.Clone(*comp.Hashes)
+	sort.SliceStable(hashes, func(i, j int) bool {
+		return hashAlgorithmStrength(hashes[i].Algorithm) > hashAlgorithmStrength(hashes[j].Algorithm)
+	})
+	return fmt.Sprintf("hash:%s:%s", hashes[0].Algorithm, strings.ToLower(hashes[0].Value))
+}
+
+// hashAlgorithmStrength ranks hash algorithms so the strongest available one identifies a package.
+func hashAlgorithmStrength(alg cyclonedx.HashAlgorithm) int {
+	switch alg {
+	case cyclonedx.HashAlgoSHA512, cyclonedx.HashAlgoSHA3_512, cyclonedx.HashAlgoBlake2b_512:
+		return 4
+	case cyclonedx.HashAlgoSHA384, cyclonedx.HashAlgoSHA3_384, cyclonedx.HashAlgoBlake2b_384:
+		return 3
+	case cyclonedx.HashAlgoSHA256, cyclonedx.HashAlgoSHA3_256, cyclonedx.HashAlgoBlake2b_256, cyclonedx.HashAlgoBlake3:
+		return 2
+	case cyclonedx.HashAlgoSHA1:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// syftLocation is a file location reported by Syft through component properties.
+type syftLocation struct {
+	index   int
+	path    string
+	layerID string
+}
+
+// syftLocations extracts the "syft:location:<n>:path" and "syft:location:<n>:layerID" properties.
+func syftLocations(comp cyclonedx.Component) []syftLocation {
+	if comp.Properties == nil {
+		return nil
+	}
+	byIndex := make(map[int]*syftLocation)
+	for _, prop := range *comp.Properties {
+		rest, ok := strings.CutPrefix(prop.Name, "syft:location:")
+		if !ok {
+			continue
+		}
+		idxStr, field, ok := strings.Cut(rest, ":")
+		if !ok {
+			continue
+		}
+		idx, err := strconv.Atoi(idxStr)
+		if err != nil {
+			continue
+		}
+		loc, ok := byIndex[idx]
+		if !ok {
+			loc = &syftLocation{index: idx}
+			byIndex[idx] = loc
+		}
+		switch field {
+		case "path":
+			loc.path = prop.Value
+		case "layerID":
+			loc.layerID = prop.Value
+		}
+	}
+
+	locations := make([]syftLocation, 0, len(byIndex))
+	for _, loc := range byIndex {
+		locations = append(locations, *loc)
+	}
+	sort.Slice(locations, func(i, j int) bool { return locations[i].index < locations[j].index })
+	return locations
+}
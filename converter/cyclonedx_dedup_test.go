@@ -0,0 +1,240 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+func TestDeduplicateComponentsRecordsOccurrenceImages(t *testing.T) {
+	for _, mode := range []CycloneDxMergeMode{MergeModeHierarchical, MergeModeFlat} {
+		t.Run(string(mode), func(t *testing.T) {
+			a := cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/a", Version: "1.0.0", BOMRef: "a"}
+			b := cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/b", Version: "1.0.0", BOMRef: "b"}
+			bom, err := CycloneDXMerge(CycloneDxMergeOptions{
+				BOMs:      []cyclonedx.BOM{resourceBOM(a, "image-a", "zlib-a"), resourceBOM(b, "image-b", "zlib-b")},
+				Name:      "acme.org/root",
+				Version:   "1.0.0",
+				MergeMode: mode,
+			})
+			if err != nil {
+				t.Fatalf("merge: %v", err)
+			}
+
+			removed, err := DeduplicateComponents(bom, nil, nil)
+			if err != nil {
+				t.Fatalf("DeduplicateComponents: %v", err)
+			}
+			if removed != 1 {
+				t.Errorf("removed %d components, want 1", removed)
+			}
+			assertUniqueRefs(t, bom)
+
+			var zlib *cyclonedx.Component
+			for i := range *bom.Components {
+				if (*bom.Components)[i].Name == "zlib" {
+					zlib = &(*bom.Components)[i]
+				}
+			}
+			if zlib == nil {
+				t.Fatalf("zlib is not a top level component: %v", componentRefs(bom))
+			}
+			var images []string
+			if zlib.Properties != nil {
+				for _, prop := range *zlib.Properties {
+					if prop.Name == occurrencePropertyPrefix+":0:image" || prop.Name == occurrencePropertyPrefix+":1:image" {
+						images = append(images, prop.Value)
+					}
+				}
+			}
+			// Hierarchical merges namespace the bom-refs of each input
+			var want []string
+			for _, ref := range componentRefs(bom) {
+				if strings.HasSuffix(ref, "image-a") || strings.HasSuffix(ref, "image-b") {
+					want = append(want, ref)
+				}
+			}
+			if len(want) != 2 || !slices.Equal(images, want) {
+				t.Errorf("occurrence images = %v, want %v", images, want)
+			}
+			for _, image := range want {
+				if !slices.Contains(dependsOn(bom, image), zlib.BOMRef) {
+					t.Errorf("%s does not depend on %s", image, zlib.BOMRef)
+				}
+			}
+		})
+	}
+}
+
+func TestDeduplicateComponentsAppliesMergePolicy(t *testing.T) {
+	zlib := func(license, hash, cpe, supplier string) func(*cyclonedx.Component) {
+		return func(comp *cyclonedx.Component) {
+			comp.Licenses = &cyclonedx.Licenses{{License: &cyclonedx.License{ID: license}}}
+			comp.Hashes = &[]cyclonedx.Hash{{Algorithm: cyclonedx.HashAlgoSHA256, Value: hash}}
+			comp.CPE = cpe
+			comp.Supplier = &cyclonedx.OrganizationalEntity{Name: supplier}
+		}
+	}
+	older := zlib("MIT", "aaaa", "cpe:2.3:a:old:zlib:1.3:*:*:*:*:*:*:*", "Old Corp")
+	newer := zlib("Zlib", "bbbb", "cpe:2.3:a:new:zlib:1.3:*:*:*:*:*:*:*", "New Corp")
+
+	tests := []struct {
+		policy       string
+		wantLicenses []string
+		wantHashes   []string
+		wantCPE      string
+		wantSupplier string
+		wantErr      bool
+	}{
+		{policy: "*=keep-first", wantLicenses: []string{"MIT"}, wantHashes: []string{"SHA-256:aaaa"}, wantCPE: "cpe:2.3:a:old:zlib:1.3:*:*:*:*:*:*:*", wantSupplier: "Old Corp"},
+		{policy: "licenses=union,hashes=union", wantLicenses: []string{"MIT", "Zlib"}, wantHashes: []string{"SHA-256:aaaa", "SHA-256:bbbb"}, wantCPE: "cpe:2.3:a:old:zlib:1.3:*:*:*:*:*:*:*", wantSupplier: "Old Corp"},
+		// The second image belongs to the newer component version, its values win
+		{policy: "*=prefer-newest", wantLicenses: []string{"Zlib"}, wantHashes: []string{"SHA-256:bbbb"}, wantCPE: "cpe:2.3:a:new:zlib:1.3:*:*:*:*:*:*:*", wantSupplier: "New Corp"},
+		{policy: "cpe=fail", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			a := cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/a", Version: "1.0.0", BOMRef: "a"}
+			b := cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/b", Version: "1.0.0", BOMRef: "b"}
+			boms := []cyclonedx.BOM{resourceBOM(a, "image-a", "zlib-a"), resourceBOM(b, "image-b", "zlib-b")}
+			older(&(*(*boms[0].Components)[0].Components)[0])
+			newer(&(*(*boms[1].Components)[0].Components)[0])
+			bom, err := CycloneDXMerge(CycloneDxMergeOptions{BOMs: boms, Name: "acme.org/root", Version: "1.0.0", MergeMode: MergeModeHierarchical})
+			if err != nil {
+				t.Fatalf("merge: %v", err)
+			}
+
+			policy, err := ParseMergePolicy(tt.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			created := map[string]time.Time{
+				"acme.org/a@1.0.0": time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				"acme.org/b@1.0.0": time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+			}
+			report := &ConflictReport{}
+			_, err = DeduplicateComponentsWithTimestamps(bom, &policy, report, created)
+
+			wantConflicts := []MergeField{MergeFieldLicenses, MergeFieldHashes, MergeFieldCPE, MergeFieldSupplier}
+			if tt.wantErr {
+				if !errors.Is(err, ErrMergeConflict) {
+					t.Fatalf("error = %v, want %v", err, ErrMergeConflict)
+				}
+				wantConflicts = wantConflicts[:3]
+			} else if err != nil {
+				t.Fatalf("DeduplicateComponentsWithTimestamps: %v", err)
+			}
+
+			var fields []MergeField
+			for _, c := range report.Conflicts {
+				fields = append(fields, c.Field)
+				if c.Component != "pkg:apk/alpine/zlib@1.3" || len(c.Sources) != 2 || !strings.HasSuffix(c.Sources[0], "image-a") || !strings.HasSuffix(c.Sources[1], "image-b") {
+					t.Errorf("conflict = %+v, want zlib found in image-a and image-b", c)
+				}
+				if want := policyStrategy(policy, c.Field); c.Resolution != want {
+					t.Errorf("resolution of %s = %s, want %s", c.Field, c.Resolution, want)
+				}
+			}
+			if !slices.Equal(fields, wantConflicts) {
+				t.Errorf("conflicts on %v, want %v", fields, wantConflicts)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var merged cyclonedx.Component
+			for _, comp := range *bom.Components {
+				if comp.Name == "zlib" {
+					merged = comp
+				}
+			}
+			var hashes []string
+			for _, h := range *merged.Hashes {
+				hashes = append(hashes, hashValue(h))
+			}
+			if got := licenseValues(merged.Licenses); !slices.Equal(got, tt.wantLicenses) {
+				t.Errorf("licenses = %v, want %v", got, tt.wantLicenses)
+			}
+			if !slices.Equal(hashes, tt.wantHashes) {
+				t.Errorf("hashes = %v, want %v", hashes, tt.wantHashes)
+			}
+			if merged.CPE != tt.wantCPE {
+				t.Errorf("cpe = %s, want %s", merged.CPE, tt.wantCPE)
+			}
+			if got := supplierValue(merged.Supplier); got != tt.wantSupplier {
+				t.Errorf("supplier = %s, want %s", got, tt.wantSupplier)
+			}
+		})
+	}
+}
+
+// policyStrategy returns the strategy of a policy for a field.
+func policyStrategy(policy MergePolicy, field MergeField) MergeStrategy {
+	switch field {
+	case MergeFieldLicenses:
+		return policy.Licenses
+	case MergeFieldHashes:
+		return policy.Hashes
+	case MergeFieldCPE:
+		return policy.CPE
+	default:
+		return policy.Supplier
+	}
+}
+
+func TestDeduplicateComponentsPrefersNewestOfThree(t *testing.T) {
+	// The newest component version is found second; the value of the third must not replace it
+	versions := []struct {
+		name    string
+		created time.Time
+		license string
+	}{
+		{"acme.org/a", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), "MIT"},
+		{"acme.org/c", time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), "Zlib"},
+		{"acme.org/b", time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), "Apache-2.0"},
+	}
+	var boms []cyclonedx.BOM
+	created := make(map[string]time.Time)
+	for i, v := range versions {
+		subject := cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: v.name, Version: "1.0.0", BOMRef: v.name}
+		bom := resourceBOM(subject, "image-"+strconv.Itoa(i), "zlib-"+strconv.Itoa(i))
+		(*(*bom.Components)[0].Components)[0].Licenses = &cyclonedx.Licenses{{License: &cyclonedx.License{ID: v.license}}}
+		boms = append(boms, bom)
+		created[v.name+"@1.0.0"] = v.created
+	}
+	bom, err := CycloneDXMerge(CycloneDxMergeOptions{BOMs: boms, Name: "acme.org/root", Version: "1.0.0", MergeMode: MergeModeHierarchical})
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	policy, err := ParseMergePolicy("licenses=prefer-newest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := &ConflictReport{}
+	if _, err := DeduplicateComponentsWithTimestamps(bom, &policy, report, created); err != nil {
+		t.Fatalf("DeduplicateComponentsWithTimestamps: %v", err)
+	}
+
+	for _, comp := range *bom.Components {
+		if comp.Name == "zlib" {
+			if got := licenseValues(comp.Licenses); !slices.Equal(got, []string{"Zlib"}) {
+				t.Errorf("licenses = %v, want the newest [Zlib]", got)
+			}
+		}
+	}
+	if len(report.Conflicts) != 2 {
+		t.Errorf("conflicts = %+v, want one per duplicate", report.Conflicts)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/CycloneDX/cyclonedx-go"
 	"github.com/google/uuid"
@@ -30,6 +32,14 @@ type CycloneDxMergeOptions struct {
 	Name      string
 	Version   string
 	MergeMode CycloneDxMergeMode
+
+	// BOMRef is the bom-ref of the merged component; empty derives "name@version".
+	BOMRef string
+
+	// Policy resolves components that share an identity but differ in metadata (flat merge only).
+	Policy *MergePolicy
+	// Conflicts collects the disagreements found while applying the policy, if set.
+	Conflicts *ConflictReport
 }
 
 // ErrMissingMetadataComponent indicates that a BOM is missing its required metadata component
@@ -45,16 +55,22 @@ func CycloneDXMerge(options CycloneDxMergeOptions) (*cyclonedx.BOM, error) {
 	var outputBom *cyclonedx.BOM
 	var err error
 
-	if options.MergeMode == MergeModeHierarchical {
-		bomSubject := &cyclonedx.Component{
+	// The flat merge keeps a subject only if one was named; it is optional there.
+	var bomSubject *cyclonedx.Component
+	if options.Name != "" {
+		bomSubject = &cyclonedx.Component{
 			Type:    cyclonedx.ComponentTypeApplication,
 			Group:   options.Group,
 			Name:    options.Name,
 			Version: options.Version,
+			BOMRef:  options.BOMRef,
 		}
+	}
+
+	if options.MergeMode == MergeModeHierarchical {
 		outputBom, err = HierarchicalMerge(options.BOMs, bomSubject)
 	} else if options.MergeMode == MergeModeFlat {
-		outputBom, err = FlatMerge(options.BOMs, nil)
+		outputBom, err = FlatMergeWithPolicy(options.BOMs, bomSubject, options.Policy, options.Conflicts)
 	} else {
 		return nil, fmt.Errorf("unsupported merge mode: %s", options.MergeMode)
 	}
@@ -139,7 +155,7 @@ func HierarchicalMerge(boms []cyclonedx.BOM, bomSubject *cyclonedx.Component) (*
 				}
 				for _, component := range *bom.Metadata.Tools.Components {
 					// Apply namespace to component
-					namespaceComponentBOMRefs(componentBOMRefNamespace(bom.Metadata.Component), &component)
+					namespaceComponentBOMRefs(bomRefNamespace(bom.Metadata.Component), &component)
 					if !containsComponent(*result.Metadata.Tools.Components, component) {
 						*result.Metadata.Tools.Components = append(*result.Metadata.Tools.Components, component)
 					}
@@ -170,15 +186,21 @@ func HierarchicalMerge(boms []cyclonedx.BOM, bomSubject *cyclonedx.Component) (*
 		}
 
 		// Add namespace to existing BOM refs (this modifies the original component!)
-		namespaceComponentBOMRefs(componentBOMRefNamespace(thisComponent), thisComponent)
+		namespaceComponentBOMRefs(bomRefNamespace(thisComponent), thisComponent)
 
 		// Ensure BOM ref is set and add top level dependency reference
 		if thisComponent.BOMRef == "" {
 			thisComponent.BOMRef = componentBOMRefNamespace(thisComponent)
 		}
-		bomSubjectDependencies = append(bomSubjectDependencies, cyclonedx.Dependency{Ref: thisComponent.BOMRef})
-
-		*result.Components = append(*result.Components, *thisComponent)
+		if result.Metadata != nil && thisComponent.BOMRef == result.Metadata.Component.BOMRef {
+			// The input describes the subject itself (e.g. the resources of a component version that
+			// also has references, with OCM bom-refs): lift its components instead of nesting a
+			// second node with the same ref
+			*result.Components = append(*result.Components, *thisComponent.Components...)
+		} else {
+			bomSubjectDependencies = append(bomSubjectDependencies, cyclonedx.Dependency{Ref: thisComponent.BOMRef})
+			*result.Components = append(*result.Components, *thisComponent)
+		}
 
 		// ComponentSbomMerge services
 		if bom.Services != nil {
@@ -195,26 +217,24 @@ func HierarchicalMerge(boms []cyclonedx.BOM, bomSubject *cyclonedx.Component) (*
 
 		// ComponentSbomMerge dependencies
 		if bom.Dependencies != nil {
-			namespaceDependencyBOMRefs(componentBOMRefNamespace(thisComponent), *bom.Dependencies)
-			*result.Dependencies = append(*result.Dependencies, *bom.Dependencies...)
+			namespaceDependencyBOMRefs(bomRefNamespace(thisComponent), *bom.Dependencies)
+			for _, dep := range *bom.Dependencies {
+				mergeDependency(result.Dependencies, dep)
+			}
 		}
 
 		// ComponentSbomMerge compositions
 		if bom.Compositions != nil {
-			namespaceCompositions(componentBOMRefNamespace(bom.Metadata.Component), *bom.Compositions)
+			namespaceCompositions(bomRefNamespace(bom.Metadata.Component), *bom.Compositions)
 			*result.Compositions = append(*result.Compositions, *bom.Compositions...)
 		}
 
-		// ComponentSbomMerge vulnerabilities - NOTE: uses result.Metadata.Component namespace, not thisComponent!
+		// ComponentSbomMerge vulnerabilities - affects refs use the same namespace as the components they point to
 		if bom.Vulnerabilities != nil {
-			var namespaceForVulns string
-			if result.Metadata != nil && result.Metadata.Component != nil {
-				namespaceForVulns = componentBOMRefNamespace(result.Metadata.Component)
-			} else {
-				namespaceForVulns = componentBOMRefNamespace(bom.Metadata.Component)
+			namespaceVulnerabilitiesRefs(bomRefNamespace(thisComponent), *bom.Vulnerabilities)
+			for _, vulnerability := range *bom.Vulnerabilities {
+				mergeVulnerability(result.Vulnerabilities, vulnerability)
 			}
-			namespaceVulnerabilitiesRefs(namespaceForVulns, *bom.Vulnerabilities)
-			*result.Vulnerabilities = append(*result.Vulnerabilities, *bom.Vulnerabilities...)
 		}
 
 		// Define local helper functions
@@ -222,7 +242,7 @@ func HierarchicalMerge(boms []cyclonedx.BOM, bomSubject *cyclonedx.Component) (*
 			namespaceBOMRefsWithComponent(thisComponent, refs)
 		}
 		namespaceReference := func(refs interface{}, propertyName string) {
-			namespaceProperty(componentBOMRefNamespace(thisComponent), refs, propertyName)
+			namespaceProperty(bomRefNamespace(thisComponent), refs, propertyName)
 		}
 
 		// ComponentSbomMerge definitions
@@ -308,11 +328,11 @@ func HierarchicalMerge(boms []cyclonedx.BOM, bomSubject *cyclonedx.Component) (*
 
 	// Add final dependency structure if bomSubject exists
 	if bomSubject != nil {
-		refs := make([]string, len(bomSubjectDependencies))
-		for i, dep := range bomSubjectDependencies {
-			refs[i] = dep.Ref
+		refs := make([]string, 0, len(bomSubjectDependencies))
+		for _, dep := range bomSubjectDependencies {
+			refs = append(refs, dep.Ref)
 		}
-		*result.Dependencies = append(*result.Dependencies, cyclonedx.Dependency{
+		mergeDependency(result.Dependencies, cyclonedx.Dependency{
 			Ref:          result.Metadata.Component.BOMRef,
 			Dependencies: &refs,
 		})
@@ -345,9 +365,28 @@ func HierarchicalMerge(boms []cyclonedx.BOM, bomSubject *cyclonedx.Component) (*
 }
 
 // FlatMerge performs a flat merge of multiple BOMs, without a hierarchical structure.
+// If bomSubject is given, it becomes the metadata component of the result and the
+// metadata component of every input BOM is kept as a top level component that the
+// subject depends on. The nodes are linked through dependencies instead of nesting.
 func FlatMerge(boms []cyclonedx.BOM, bomSubject *cyclonedx.Component) (*cyclonedx.BOM, error) {
+	return FlatMergeWithPolicy(boms, bomSubject, nil, nil)
+}
+
+// FlatMergeWithPolicy performs a flat merge like FlatMerge. If a policy is given, components
+// are also matched by purl (or hash) and differing metadata is resolved field by field;
+// every disagreement is added to the report.
+func FlatMergeWithPolicy(boms []cyclonedx.BOM, bomSubject *cyclonedx.Component, policy *MergePolicy, report *ConflictReport) (*cyclonedx.BOM, error) {
 	result := &cyclonedx.BOM{}
 
+	if bomSubject != nil {
+		if bomSubject.BOMRef == "" {
+			bomSubject.BOMRef = componentBOMRefNamespace(bomSubject)
+		}
+		result.Metadata = &cyclonedx.Metadata{
+			Component: bomSubject,
+		}
+	}
+
 	// Initialize lists
 	result.Components = &[]cyclonedx.Component{}
 	result.Services = &[]cyclonedx.Service{}
@@ -356,14 +395,79 @@ func FlatMerge(boms []cyclonedx.BOM, bomSubject *cyclonedx.Component) (*cycloned
 	result.Compositions = &[]cyclonedx.Composition{}
 	result.Vulnerabilities = &[]cyclonedx.Vulnerability{}
 
+	index := newComponentIndex(result.Components, policy, report)
+	var bomSubjectDependencies []string
+
 	for _, bom := range boms {
+		source := bomMergeSource(bom)
+
+		// refMap maps bom-refs of this input to the bom-ref of an equal component merged earlier
+		refMap := make(map[string]string)
+		addComponent := func(comp cyclonedx.Component) error {
+			ref, err := index.add(comp, source)
+			if err != nil {
+				return err
+			}
+			if comp.BOMRef != "" && ref != comp.BOMRef {
+				refMap[comp.BOMRef] = ref
+			}
+			return nil
+		}
+		mapRef := func(ref string) string {
+			if mapped, ok := refMap[ref]; ok {
+				return mapped
+			}
+			return ref
+		}
+
+		// Keep the input subject as a top level node when merging into a new subject
+		var node *cyclonedx.Component
+		if bomSubject != nil && bom.Metadata != nil && bom.Metadata.Component != nil {
+			n := *bom.Metadata.Component
+			node = &n
+			if node.BOMRef == "" {
+				node.BOMRef = componentBOMRefNamespace(node)
+			}
+			nested := node.Components
+			node.Components = nil
+			if !containsComponent(*result.Components, *node) {
+				if err := addComponent(*node); err != nil {
+					return nil, err
+				}
+			}
+			bomSubjectDependencies = append(bomSubjectDependencies, node.BOMRef)
+
+			// Nested components of the subject are promoted to the top level
+			if nested != nil {
+				for _, comp := range flattenComponents(*nested) {
+					if err := addComponent(comp); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+
 		// Merge components
 		if bom.Components != nil {
+			for _, comp := range flattenComponents(*bom.Components) {
+				if err := addComponent(comp); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		// Scanners usually don't emit an edge from their subject to the packages;
+		// without nesting, that edge is the only link between the node and its content.
+		if node != nil && bom.Components != nil && !containsDependency(bom.Dependencies, node.BOMRef) {
+			refs := make([]string, 0, len(*bom.Components))
 			for _, comp := range *bom.Components {
-				if !containsComponent(*result.Components, comp) {
-					*result.Components = append(*result.Components, comp)
+				if comp.BOMRef != "" {
+					refs = append(refs, mapRef(comp.BOMRef))
 				}
 			}
+			if len(refs) > 0 {
+				mergeDependency(result.Dependencies, cyclonedx.Dependency{Ref: node.BOMRef, Dependencies: &refs})
+			}
 		}
 
 		// Merge services
@@ -382,7 +486,17 @@ func FlatMerge(boms []cyclonedx.BOM, bomSubject *cyclonedx.Component) (*cycloned
 
 		// Merge dependencies
 		if bom.Dependencies != nil {
-			*result.Dependencies = append(*result.Dependencies, *bom.Dependencies...)
+			for _, dep := range *bom.Dependencies {
+				dep.Ref = mapRef(dep.Ref)
+				if dep.Dependencies != nil {
+					refs := make([]string, len(*dep.Dependencies))
+					for i, ref := range *dep.Dependencies {
+						refs[i] = mapRef(ref)
+					}
+					dep.Dependencies = &refs
+				}
+				mergeDependency(result.Dependencies, dep)
+			}
 		}
 
 		// Merge compositions
@@ -392,10 +506,27 @@ func FlatMerge(boms []cyclonedx.BOM, bomSubject *cyclonedx.Component) (*cycloned
 
 		// Merge vulnerabilities
 		if bom.Vulnerabilities != nil {
-			*result.Vulnerabilities = append(*result.Vulnerabilities, *bom.Vulnerabilities...)
+			for _, vulnerability := range *bom.Vulnerabilities {
+				if vulnerability.Affects != nil {
+					affects := slices.Clone(*vulnerability.Affects)
+					for i := range affects {
+						affects[i].Ref = mapRef(affects[i].Ref)
+					}
+					vulnerability.Affects = &affects
+				}
+				mergeVulnerability(result.Vulnerabilities, vulnerability)
+			}
 		}
 	}
 
+	// Add final dependency structure if bomSubject exists
+	if bomSubject != nil {
+		mergeDependency(result.Dependencies, cyclonedx.Dependency{
+			Ref:          bomSubject.BOMRef,
+			Dependencies: &bomSubjectDependencies,
+		})
+	}
+
 	// Cleanup empty top level elements
 	if len(*result.Components) == 0 {
 		result.Components = nil
@@ -421,16 +552,26 @@ func FlatMerge(boms []cyclonedx.BOM, bomSubject *cyclonedx.Component) (*cycloned
 
 // Helper functions
 
-// componentBOMRefNamespace generates a namespace for a component's BOM reference
+// componentBOMRefNamespace generates a namespace for a component's BOM reference. OCM resources
+// sharing name and version are told apart by their extra identity.
 func componentBOMRefNamespace(component *cyclonedx.Component) string {
 	if component == nil {
 		return ""
 	}
 
 	if component.Group != "" {
-		return fmt.Sprintf("%s.%s@%s", component.Group, component.Name, component.Version)
+		return fmt.Sprintf("%s.%s@%s%s", component.Group, component.Name, component.Version, resourceExtraIdentity(component))
 	}
-	return fmt.Sprintf("%s@%s", component.Name, component.Version)
+	return fmt.Sprintf("%s@%s%s", component.Name, component.Version, resourceExtraIdentity(component))
+}
+
+// bomRefNamespace returns the namespace applied to the refs of a BOM merged below component.
+// Components with an OCM scheme ref carry globally unique refs and are not namespaced.
+func bomRefNamespace(component *cyclonedx.Component) string {
+	if component != nil && isOCMBOMRef(component.BOMRef) {
+		return ""
+	}
+	return componentBOMRefNamespace(component)
 }
 
 // namespacedBOMRef creates a namespaced BOM reference
@@ -438,7 +579,7 @@ func namespacedBOMRef(component *cyclonedx.Component, bomRef string) string {
 	if bomRef == "" {
 		return ""
 	}
-	return namespacedBOMRefWithNamespace(componentBOMRefNamespace(component), bomRef)
+	return namespacedBOMRefWithNamespace(bomRefNamespace(component), bomRef)
 }
 
 // namespacedBOMRefWithNamespace creates a namespaced BOM reference with namespace string
@@ -457,7 +598,7 @@ func namespaceBOMRefsWithComponent(bomSubject *cyclonedx.Component, references i
 	if references == nil {
 		return
 	}
-	namespace := componentBOMRefNamespace(bomSubject)
+	namespace := bomRefNamespace(bomSubject)
 	namespaceBOMRefs(namespace, references)
 }
 
@@ -623,30 +764,71 @@ func namespaceCompositions(bomRefNamespace string, compositions []cyclonedx.Comp
 	}
 }
 
-// namespaceVulnerabilitiesRefs adds namespace to vulnerability BOM references using stack
+// namespaceVulnerabilitiesRefs adds namespace to vulnerability BOM references and to the refs of the
+// affected components, so they keep pointing at the namespaced component bom-refs
 func namespaceVulnerabilitiesRefs(bomRefNamespace string, vulnerabilities []cyclonedx.Vulnerability) {
 	if bomRefNamespace == "" {
 		return
 	}
 
-	pendingVulnerabilities := make([]*cyclonedx.Vulnerability, len(vulnerabilities))
 	for i := range vulnerabilities {
-		pendingVulnerabilities[i] = &vulnerabilities[i]
-	}
-
-	for len(pendingVulnerabilities) > 0 {
-		vulnerability := pendingVulnerabilities[len(pendingVulnerabilities)-1]
-		pendingVulnerabilities = pendingVulnerabilities[:len(pendingVulnerabilities)-1]
-
+		vulnerability := &vulnerabilities[i]
 		vulnerability.BOMRef = namespacedBOMRefWithNamespace(bomRefNamespace, vulnerability.BOMRef)
 
 		if vulnerability.Affects != nil {
-			for j := range *vulnerability.Affects {
-				affect := &(*vulnerability.Affects)[j]
-				affect.Ref = bomRefNamespace
+			affects := slices.Clone(*vulnerability.Affects)
+			for j := range affects {
+				// BOM-Links point into other BOMs and must stay untouched
+				if !strings.HasPrefix(affects[j].Ref, "urn:cdx:") {
+					affects[j].Ref = namespacedBOMRefWithNamespace(bomRefNamespace, affects[j].Ref)
+				}
+			}
+			vulnerability.Affects = &affects
+		}
+	}
+}
+
+// mergeVulnerability adds a vulnerability to the list. A vulnerability with the same ID that is
+// already present is extended with the affected components (and versions) of the new one instead.
+func mergeVulnerability(vulnerabilities *[]cyclonedx.Vulnerability, vulnerability cyclonedx.Vulnerability) {
+	idx := -1
+	if vulnerability.ID != "" {
+		idx = slices.IndexFunc(*vulnerabilities, func(v cyclonedx.Vulnerability) bool { return v.ID == vulnerability.ID })
+	}
+	if idx < 0 {
+		*vulnerabilities = append(*vulnerabilities, vulnerability)
+		return
+	}
+
+	existing := &(*vulnerabilities)[idx]
+	if vulnerability.Affects == nil {
+		return
+	}
+	affects := []cyclonedx.Affects{}
+	if existing.Affects != nil {
+		affects = slices.Clone(*existing.Affects)
+	}
+	for _, affect := range *vulnerability.Affects {
+		i := slices.IndexFunc(affects, func(a cyclonedx.Affects) bool { return a.Ref == affect.Ref })
+		if i < 0 {
+			affects = append(affects, affect)
+			continue
+		}
+		if affect.Range == nil {
+			continue
+		}
+		versions := []cyclonedx.AffectedVersions{}
+		if affects[i].Range != nil {
+			versions = slices.Clone(*affects[i].Range)
+		}
+		for _, version := range *affect.Range {
+			if !slices.Contains(versions, version) {
+				versions = append(versions, version)
 			}
 		}
+		affects[i].Range = &versions
 	}
+	existing.Affects = &affects
 }
 
 // containsComponent checks if a component already exists in the slice
@@ -659,6 +841,61 @@ func containsComponent(components []cyclonedx.Component, target cyclonedx.Compon
 	return false
 }
 
+// containsDependency checks if the dependency list has an entry for the given ref
+func containsDependency(dependencies *[]cyclonedx.Dependency, ref string) bool {
+	if dependencies == nil {
+		return false
+	}
+	for _, dep := range *dependencies {
+		if dep.Ref == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeDependency adds a dependency entry, unioning the dependsOn list with an existing entry for the same ref
+func mergeDependency(dependencies *[]cyclonedx.Dependency, dependency cyclonedx.Dependency) {
+	for i := range *dependencies {
+		existing := &(*dependencies)[i]
+		if existing.Ref != dependency.Ref {
+			continue
+		}
+		if dependency.Dependencies == nil {
+			return
+		}
+		if existing.Dependencies == nil {
+			existing.Dependencies = &[]string{}
+		}
+		for _, ref := range *dependency.Dependencies {
+			if !slices.Contains(*existing.Dependencies, ref) {
+				*existing.Dependencies = append(*existing.Dependencies, ref)
+			}
+		}
+		return
+	}
+
+	if dependency.Dependencies != nil {
+		refs := slices.Clone(*dependency.Dependencies)
+		dependency.Dependencies = &refs
+	}
+	*dependencies = append(*dependencies, dependency)
+}
+
+// flattenComponents returns the components and all of their nested components as a flat list
+func flattenComponents(components []cyclonedx.Component) []cyclonedx.Component {
+	var result []cyclonedx.Component
+	for _, comp := range components {
+		nested := comp.Components
+		comp.Components = nil
+		result = append(result, comp)
+		if nested != nil {
+			result = append(result, flattenComponents(*nested)...)
+		}
+	}
+	return result
+}
+
 // containsService checks if a service already exists in the slice
 func containsService(services []cyclonedx.Service, target cyclonedx.Service) bool {
 	for _, svc := range services {
@@ -0,0 +1,294 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+// componentRefs returns the bom-refs of all components of a BOM, nested ones included, and of
+// its metadata component.
+func componentRefs(bom *cyclonedx.BOM) []string {
+	var refs []string
+	var walk func(components []cyclonedx.Component)
+	walk = func(components []cyclonedx.Component) {
+		for _, comp := range components {
+			refs = append(refs, comp.BOMRef)
+			if comp.Components != nil {
+				walk(*comp.Components)
+			}
+		}
+	}
+	if bom.Metadata != nil && bom.Metadata.Component != nil {
+		refs = append(refs, bom.Metadata.Component.BOMRef)
+	}
+	if bom.Components != nil {
+		walk(*bom.Components)
+	}
+	return refs
+}
+
+// assertUniqueRefs fails if a bom-ref is used by more than one component or dependency entry,
+// or a dependency entry depends on itself.
+func assertUniqueRefs(t *testing.T, bom *cyclonedx.BOM) {
+	t.Helper()
+	seen := make(map[string]bool)
+	for _, ref := range componentRefs(bom) {
+		if seen[ref] {
+			t.Errorf("duplicate component bom-ref %q", ref)
+		}
+		seen[ref] = true
+	}
+	if bom.Dependencies == nil {
+		return
+	}
+	entries := make(map[string]bool)
+	for _, dep := range *bom.Dependencies {
+		if entries[dep.Ref] {
+			t.Errorf("duplicate dependency entry for %q", dep.Ref)
+		}
+		entries[dep.Ref] = true
+		if dep.Dependencies != nil && slices.Contains(*dep.Dependencies, dep.Ref) {
+			t.Errorf("dependency entry %q depends on itself", dep.Ref)
+		}
+	}
+}
+
+// dependsOn returns the refs a dependency entry depends on.
+func dependsOn(bom *cyclonedx.BOM, ref string) []string {
+	if bom.Dependencies == nil {
+		return nil
+	}
+	for _, dep := range *bom.Dependencies {
+		if dep.Ref == ref && dep.Dependencies != nil {
+			return *dep.Dependencies
+		}
+	}
+	return nil
+}
+
+// resourceBOM returns a BOM of one image resource with one package, as produced for the
+// resources of a component version.
+func resourceBOM(subject cyclonedx.Component, resourceRef, packageRef string) cyclonedx.BOM {
+	return cyclonedx.BOM{
+		Metadata: &cyclonedx.Metadata{Component: &subject},
+		Components: &[]cyclonedx.Component{{
+			Type:   cyclonedx.ComponentTypeContainer,
+			Name:   "image",
+			BOMRef: resourceRef,
+			Components: &[]cyclonedx.Component{{
+				Type:       cyclonedx.ComponentTypeLibrary,
+				Name:       "zlib",
+				Version:    "1.3",
+				PackageURL: "pkg:apk/alpine/zlib@1.3",
+				BOMRef:     packageRef,
+			}},
+		}},
+		Dependencies: &[]cyclonedx.Dependency{
+			{Ref: subject.BOMRef, Dependencies: &[]string{resourceRef}},
+			{Ref: resourceRef, Dependencies: &[]string{packageRef}},
+		},
+	}
+}
+
+func TestHierarchicalMergeLiftsSubjectWithOCMBOMRefs(t *testing.T) {
+	appRef := OCMComponentBOMRef("acme.org/app", "1.0.0")
+	libRef := OCMComponentBOMRef("acme.org/lib", "1.0.0")
+	appResource := OCMResourceBOMRef("acme.org/app", "1.0.0", "image", nil)
+	libResource := OCMResourceBOMRef("acme.org/lib", "1.0.0", "image", nil)
+
+	// The app component version has its own resources and references the lib component version
+	own := resourceBOM(cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/app", Version: "1.0.0", BOMRef: appRef},
+		appResource, appResource+"/pkg:apk/alpine/zlib@1.3")
+	child := resourceBOM(cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/lib", Version: "1.0.0", BOMRef: libRef},
+		libResource, libResource+"/pkg:apk/alpine/zlib@1.3")
+
+	subject := &cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/app", Version: "1.0.0", BOMRef: appRef}
+	merged, err := HierarchicalMerge([]cyclonedx.BOM{own, child}, subject)
+	if err != nil {
+		t.Fatalf("HierarchicalMerge: %v", err)
+	}
+
+	assertUniqueRefs(t, merged)
+	if merged.Metadata.Component.BOMRef != appRef {
+		t.Errorf("subject ref = %q, want %q", merged.Metadata.Component.BOMRef, appRef)
+	}
+	refs := dependsOn(merged, appRef)
+	for _, want := range []string{appResource, libRef} {
+		if !slices.Contains(refs, want) {
+			t.Errorf("subject dependsOn %v, missing %q", refs, want)
+		}
+	}
+	// The resource of the app is lifted to the top level next to the lib component version
+	var top []string
+	for _, comp := range *merged.Components {
+		top = append(top, comp.BOMRef)
+	}
+	if !slices.Equal(top, []string{appResource, libRef}) {
+		t.Errorf("top level components = %v, want [%s %s]", top, appResource, libRef)
+	}
+}
+
+// vulnerableBOM returns a BOM of a component version with one package affected by CVE-2024-0001
+// twice, with different version ranges, as scanners report one match per range.
+func vulnerableBOM(name, packageRef string) cyclonedx.BOM {
+	affected := func(version string) *[]cyclonedx.Affects {
+		return &[]cyclonedx.Affects{{Ref: packageRef, Range: &[]cyclonedx.AffectedVersions{{Version: version, Status: cyclonedx.VulnerabilityStatusAffected}}}}
+	}
+	return cyclonedx.BOM{
+		Metadata: &cyclonedx.Metadata{Component: &cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: name, Version: "1.0.0"}},
+		Components: &[]cyclonedx.Component{{
+			Type: cyclonedx.ComponentTypeLibrary, Name: "zlib", Version: "1.3", BOMRef: packageRef,
+		}},
+		Vulnerabilities: &[]cyclonedx.Vulnerability{
+			{BOMRef: "vuln-1", ID: "CVE-2024-0001", Affects: affected("1.3")},
+			{BOMRef: "vuln-2", ID: "CVE-2024-0001", Affects: affected("1.3.1")},
+		},
+	}
+}
+
+func TestHierarchicalMergeNamespacesVulnerabilitiesAcrossLevels(t *testing.T) {
+	lib := vulnerableBOM("lib", "pkg:apk/alpine/zlib@1.3")
+	tool := vulnerableBOM("tool", "pkg:apk/alpine/zlib@1.3")
+
+	// Level 2: the mid component version references lib
+	mid, err := HierarchicalMerge([]cyclonedx.BOM{lib}, &cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "mid", Version: "1.0.0"})
+	if err != nil {
+		t.Fatalf("HierarchicalMerge mid: %v", err)
+	}
+	// Level 3: the root references mid and tool
+	root, err := HierarchicalMerge([]cyclonedx.BOM{*mid, tool}, &cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "root", Version: "1.0.0"})
+	if err != nil {
+		t.Fatalf("HierarchicalMerge root: %v", err)
+	}
+
+	assertUniqueRefs(t, root)
+	if root.Vulnerabilities == nil || len(*root.Vulnerabilities) != 1 {
+		t.Fatalf("vulnerabilities = %+v, want CVE-2024-0001 once", root.Vulnerabilities)
+	}
+	vuln := (*root.Vulnerabilities)[0]
+	if vuln.Affects == nil || len(*vuln.Affects) != 2 {
+		t.Fatalf("affects = %+v, want the zlib packages of lib and tool", vuln.Affects)
+	}
+
+	refs := componentRefs(root)
+	want := map[string]string{
+		"lib":  "mid@1.0.0:lib@1.0.0:pkg:apk/alpine/zlib@1.3",
+		"tool": "tool@1.0.0:pkg:apk/alpine/zlib@1.3",
+	}
+	for _, affect := range *vuln.Affects {
+		if !slices.Contains(refs, affect.Ref) {
+			t.Errorf("affects ref %q does not resolve to a component, refs: %v", affect.Ref, refs)
+		}
+		if affect.Range == nil || len(*affect.Range) != 2 {
+			t.Errorf("ranges of %q = %+v, want 1.3 and 1.3.1", affect.Ref, affect.Range)
+		}
+	}
+	for name, ref := range want {
+		if !slices.ContainsFunc(*vuln.Affects, func(a cyclonedx.Affects) bool { return a.Ref == ref }) {
+			t.Errorf("affects of %s: missing namespaced ref %q", name, ref)
+		}
+	}
+}
+
+func TestFlatMergeLinksSubjectsToTheirPackages(t *testing.T) {
+	// Syft does not link its subject to the packages; only packages depend on each other
+	image := func(name string) cyclonedx.BOM {
+		return cyclonedx.BOM{
+			Metadata: &cyclonedx.Metadata{Component: &cyclonedx.Component{Type: cyclonedx.ComponentTypeContainer, Name: name, BOMRef: name}},
+			Components: &[]cyclonedx.Component{
+				{Type: cyclonedx.ComponentTypeLibrary, Name: "zlib", Version: "1.3", BOMRef: name + "/zlib"},
+				{Type: cyclonedx.ComponentTypeLibrary, Name: "openssl", Version: "3.3", BOMRef: name + "/openssl"},
+			},
+			Dependencies: &[]cyclonedx.Dependency{{Ref: name + "/openssl", Dependencies: &[]string{name + "/zlib"}}},
+		}
+	}
+	appRef := OCMComponentBOMRef("acme.org/app", "1.0.0")
+	subject := &cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/app", Version: "1.0.0", BOMRef: appRef}
+	merged, err := FlatMergeWithPolicy([]cyclonedx.BOM{image("frontend"), image("backend")}, subject, nil, nil)
+	if err != nil {
+		t.Fatalf("FlatMergeWithPolicy: %v", err)
+	}
+
+	assertUniqueRefs(t, merged)
+	if got := dependsOn(merged, appRef); !slices.Equal(got, []string{"frontend", "backend"}) {
+		t.Errorf("subject dependsOn %v, want [frontend backend]", got)
+	}
+	for _, name := range []string{"frontend", "backend"} {
+		if got, want := dependsOn(merged, name), []string{name + "/zlib", name + "/openssl"}; !slices.Equal(got, want) {
+			t.Errorf("%s dependsOn %v, want %v", name, got, want)
+		}
+		if got, want := dependsOn(merged, name+"/openssl"), []string{name + "/zlib"}; !slices.Equal(got, want) {
+			t.Errorf("%s/openssl dependsOn %v, want %v", name, got, want)
+		}
+	}
+}
+
+// ocmTreeLines returns the nodes of the OCM tree of a BOM as indented "kind label" lines.
+func ocmTreeLines(t *testing.T, bom *cyclonedx.BOM) []string {
+	t.Helper()
+	tree, err := BuildOCMTree(bom)
+	if err != nil {
+		t.Fatalf("BuildOCMTree: %v", err)
+	}
+	var lines []string
+	var walk func(node *OCMNode, indent string)
+	walk = func(node *OCMNode, indent string) {
+		lines = append(lines, indent+string(node.Kind)+" "+node.Label())
+		for _, child := range node.Children {
+			walk(child, indent+"  ")
+		}
+	}
+	walk(tree, "")
+	return lines
+}
+
+func TestBuildOCMTreeIsTheSameForFlatAndHierarchicalMerges(t *testing.T) {
+	appRef := OCMComponentBOMRef("acme.org/app", "1.0.0")
+	libRef := OCMComponentBOMRef("acme.org/lib", "1.0.0")
+	appResource := OCMResourceBOMRef("acme.org/app", "1.0.0", "image", nil)
+	libResource := OCMResourceBOMRef("acme.org/lib", "1.0.0", "image", nil)
+	inputs := func() []cyclonedx.BOM {
+		return []cyclonedx.BOM{
+			resourceBOM(cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/app", Version: "1.0.0", BOMRef: appRef},
+				appResource, appResource+"/pkg:apk/alpine/zlib@1.3"),
+			resourceBOM(cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/lib", Version: "1.0.0", BOMRef: libRef},
+				libResource, libResource+"/pkg:apk/alpine/zlib@1.3"),
+		}
+	}
+	subject := func() *cyclonedx.Component {
+		return &cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/app", Version: "1.0.0", BOMRef: appRef}
+	}
+
+	hierarchical, err := HierarchicalMerge(inputs(), subject())
+	if err != nil {
+		t.Fatalf("HierarchicalMerge: %v", err)
+	}
+	flat, err := FlatMergeWithPolicy(inputs(), subject(), nil, nil)
+	if err != nil {
+		t.Fatalf("FlatMergeWithPolicy: %v", err)
+	}
+
+	want := []string{
+		"component acme.org/app@1.0.0",
+		"  resource image",
+		"    package zlib@1.3",
+		"  component acme.org/lib@1.0.0",
+		"    resource image",
+		"      package zlib@1.3",
+	}
+	if got := ocmTreeLines(t, hierarchical); !slices.Equal(got, want) {
+		t.Errorf("tree of the hierarchical merge =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if got := ocmTreeLines(t, flat); !slices.Equal(got, want) {
+		t.Errorf("tree of the flat merge =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -26,6 +26,10 @@ type CycloneDXProcessorOptions struct {
 	Name       string
 	Version    string
 	Properties []cyclonedx.Property
+
+	Supplier           *cyclonedx.OrganizationalEntity
+	Manufacturer       *cyclonedx.OrganizationalEntity
+	ExternalReferences []cyclonedx.ExternalReference
 }
 
 type cyclonedxProcessorImpl struct{}
@@ -79,6 +83,18 @@ func (p *cyclonedxProcessorImpl) Edit(b *cyclonedx.BOM, opts CycloneDXProcessorO
 		props := opts.Properties
 		root.Properties = &props
 	}
+	if opts.Supplier != nil {
+		root.Supplier = opts.Supplier
+	}
+	if opts.Manufacturer != nil {
+		root.Manufacturer = opts.Manufacturer
+	}
+	if len(opts.ExternalReferences) > 0 {
+		if root.ExternalReferences == nil {
+			root.ExternalReferences = &[]cyclonedx.ExternalReference{}
+		}
+		*root.ExternalReferences = append(*root.ExternalReferences, opts.ExternalReferences...)
+	}
 	return nil
 }
 
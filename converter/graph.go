@@ -0,0 +1,216 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+// GraphFormat selects the graph description language.
+type GraphFormat string
+
+const (
+	GraphFormatDOT     GraphFormat = "dot"
+	GraphFormatMermaid GraphFormat = "mermaid"
+)
+
+// GraphCollapse selects which nodes of the OCM tree are drawn.
+type GraphCollapse string
+
+const (
+	// GraphCollapseComponents draws component versions only.
+	GraphCollapseComponents GraphCollapse = "components"
+	// GraphCollapseResources draws component versions and their resources.
+	GraphCollapseResources GraphCollapse = "resources"
+	// GraphCollapseFull draws packages and their dependencies as well.
+	GraphCollapseFull GraphCollapse = "full"
+)
+
+// GraphOptions configures RenderGraph.
+type GraphOptions struct {
+	Format   GraphFormat
+	Collapse GraphCollapse
+	// MaxDepth limits the number of component levels below the root; zero or less draws all.
+	MaxDepth int
+}
+
+// graphNode is a node to draw.
+type graphNode struct {
+	id   string
+	kind OCMNodeKind
+	node *OCMNode
+}
+
+// graphEdge is an edge to draw; dependency edges between packages are drawn dashed.
+type graphEdge struct {
+	from, to   string
+	dependency bool
+}
+
+// RenderGraph draws the OCM tree of a merged BOM: component versions, optionally their resources
+// and, in full mode, the packages with the dependencies between them.
+func RenderGraph(bom *cyclonedx.BOM, w io.Writer, opts GraphOptions) error {
+	tree, err := BuildOCMTree(bom)
+	if err != nil {
+		return err
+	}
+	if opts.Collapse == "" {
+		opts.Collapse = GraphCollapseResources
+	}
+
+	var nodes []graphNode
+	var edges []graphEdge
+	ids := make(map[string]string) // bom-ref -> node id
+	edgeSeen := make(map[graphEdge]bool)
+	addEdge := func(edge graphEdge) {
+		if !edgeSeen[edge] {
+			edgeSeen[edge] = true
+			edges = append(edges, edge)
+		}
+	}
+	nodeID := func(node *OCMNode) (string, bool) {
+		key := node.Ref()
+		if key == "" {
+			key = fmt.Sprintf("%p", node.Component)
+		}
+		if id, ok := ids[key]; ok {
+			return id, false
+		}
+		id := fmt.Sprintf("n%d", len(ids))
+		ids[key] = id
+		nodes = append(nodes, graphNode{id: id, kind: node.Kind, node: node})
+		return id, true
+	}
+
+	var walk func(node *OCMNode, parentID string)
+	walk = func(node *OCMNode, parentID string) {
+		switch node.Kind {
+		case OCMNodeComponent:
+			if opts.MaxDepth > 0 && node.Depth() > opts.MaxDepth {
+				return
+			}
+		case OCMNodeResource:
+			if opts.Collapse == GraphCollapseComponents {
+				return
+			}
+		case OCMNodePackage:
+			if opts.Collapse != GraphCollapseFull {
+				return
+			}
+		}
+
+		id, isNew := nodeID(node)
+		if parentID != "" {
+			addEdge(graphEdge{from: parentID, to: id})
+		}
+		if !isNew {
+			return
+		}
+		for _, child := range node.Children {
+			walk(child, id)
+		}
+	}
+	walk(tree, "")
+
+	// Package dependencies between drawn packages
+	if opts.Collapse == GraphCollapseFull && bom.Dependencies != nil {
+		for _, dep := range *bom.Dependencies {
+			from, ok := ids[dep.Ref]
+			if !ok || dep.Dependencies == nil {
+				continue
+			}
+			for _, ref := range *dep.Dependencies {
+				if to, ok := ids[ref]; ok && to != from && !edgeSeen[graphEdge{from: from, to: to}] {
+					addEdge(graphEdge{from: from, to: to, dependency: true})
+				}
+			}
+		}
+	}
+
+	switch opts.Format {
+	case GraphFormatDOT, "":
+		return writeDOT(w, tree.Label(), nodes, edges)
+	case GraphFormatMermaid:
+		return writeMermaid(w, nodes, edges)
+	default:
+		return fmt.Errorf("unsupported graph format: %s", opts.Format)
+	}
+}
+
+// writeDOT writes the graph in Graphviz DOT.
+func writeDOT(w io.Writer, title string, nodes []graphNode, edges []graphEdge) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(title))
+	b.WriteString("  rankdir=LR;\n  node [fontname=\"Helvetica\"];\n")
+	for _, n := range nodes {
+		label := n.node.Component.Name
+		if n.node.Component.Version != "" {
+			label += "\n" + n.node.Component.Version
+		}
+		var attrs string
+		switch n.kind {
+		case OCMNodeComponent:
+			attrs = `shape=box, style="rounded,bold"`
+		case OCMNodeResource:
+			attrs = "shape=component"
+		default:
+			attrs = "shape=ellipse"
+		}
+		fmt.Fprintf(&b, "  %s [label=%s, %s];\n", n.id, dotQuote(label), attrs)
+	}
+	for _, e := range edges {
+		if e.dependency {
+			fmt.Fprintf(&b, "  %s -> %s [style=dashed];\n", e.from, e.to)
+		} else {
+			fmt.Fprintf(&b, "  %s -> %s;\n", e.from, e.to)
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMermaid writes the graph as a Mermaid flowchart.
+func writeMermaid(w io.Writer, nodes []graphNode, edges []graphEdge) error {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for _, n := range nodes {
+		label := mermaidQuote(n.node.Label())
+		switch n.kind {
+		case OCMNodeComponent:
+			fmt.Fprintf(&b, "  %s[%s]\n", n.id, label)
+		case OCMNodeResource:
+			fmt.Fprintf(&b, "  %s[[%s]]\n", n.id, label)
+		default:
+			fmt.Fprintf(&b, "  %s(%s)\n", n.id, label)
+		}
+	}
+	for _, e := range edges {
+		if e.dependency {
+			fmt.Fprintf(&b, "  %s -.-> %s\n", e.from, e.to)
+		} else {
+			fmt.Fprintf(&b, "  %s --> %s\n", e.from, e.to)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote quotes a DOT string, keeping line breaks as \n.
+func dotQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
+
+// mermaidQuote quotes a Mermaid label.
+func mermaidQuote(s string) string {
+	return `"` + strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s) + `"`
+}
@@ -0,0 +1,215 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+// graphBOM returns three levels of component versions with one image each; openssl depends on
+// zlib, which is found in two images.
+func graphBOM() *cyclonedx.BOM {
+	pkg := func(name, version string) cyclonedx.Component {
+		return cyclonedx.Component{Type: cyclonedx.ComponentTypeLibrary, Name: name, Version: version, BOMRef: name}
+	}
+	image := func(name string, packages ...cyclonedx.Component) cyclonedx.Component {
+		return cyclonedx.Component{Type: cyclonedx.ComponentTypeContainer, Name: name, BOMRef: name, Components: &packages}
+	}
+	bom := cyclonedx.NewBOM()
+	bom.Metadata = &cyclonedx.Metadata{Component: &cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/app", Version: "1.0.0", BOMRef: "app"}}
+	bom.Components = &[]cyclonedx.Component{
+		image("frontend", pkg("zlib", "1.3"), pkg("openssl", "3.3")),
+		{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/lib", Version: "2.0.0", BOMRef: "lib", Components: &[]cyclonedx.Component{
+			image("backend", pkg("zlib", "1.3")),
+			{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/base", Version: "3.0.0", BOMRef: "base", Components: &[]cyclonedx.Component{
+				image("os", pkg("musl", "1.2.5")),
+			}},
+		}},
+	}
+	bom.Dependencies = &[]cyclonedx.Dependency{
+		{Ref: "app", Dependencies: &[]string{"frontend", "lib"}},
+		{Ref: "lib", Dependencies: &[]string{"backend", "base"}},
+		{Ref: "base", Dependencies: &[]string{"os"}},
+		{Ref: "openssl", Dependencies: &[]string{"zlib"}},
+	}
+	return bom
+}
+
+func TestRenderGraph(t *testing.T) {
+	tests := []struct {
+		name string
+		opts GraphOptions
+		want string
+	}{
+		{
+			name: "components dot",
+			opts: GraphOptions{Format: GraphFormatDOT, Collapse: GraphCollapseComponents},
+			want: `digraph "acme.org/app@1.0.0" {
+  rankdir=LR;
+  node [fontname="Helvetica"];
+  n0 [label="acme.org/app\n1.0.0", shape=box, style="rounded,bold"];
+  n1 [label="acme.org/lib\n2.0.0", shape=box, style="rounded,bold"];
+  n2 [label="acme.org/base\n3.0.0", shape=box, style="rounded,bold"];
+  n0 -> n1;
+  n1 -> n2;
+}
+`,
+		},
+		{
+			name: "components mermaid",
+			opts: GraphOptions{Format: GraphFormatMermaid, Collapse: GraphCollapseComponents},
+			want: `graph LR
+  n0["acme.org/app@1.0.0"]
+  n1["acme.org/lib@2.0.0"]
+  n2["acme.org/base@3.0.0"]
+  n0 --> n1
+  n1 --> n2
+`,
+		},
+		{
+			name: "resources dot",
+			opts: GraphOptions{Format: GraphFormatDOT},
+			want: `digraph "acme.org/app@1.0.0" {
+  rankdir=LR;
+  node [fontname="Helvetica"];
+  n0 [label="acme.org/app\n1.0.0", shape=box, style="rounded,bold"];
+  n1 [label="frontend", shape=component];
+  n2 [label="acme.org/lib\n2.0.0", shape=box, style="rounded,bold"];
+  n3 [label="backend", shape=component];
+  n4 [label="acme.org/base\n3.0.0", shape=box, style="rounded,bold"];
+  n5 [label="os", shape=component];
+  n0 -> n1;
+  n0 -> n2;
+  n2 -> n3;
+  n2 -> n4;
+  n4 -> n5;
+}
+`,
+		},
+		{
+			name: "resources mermaid",
+			opts: GraphOptions{Format: GraphFormatMermaid, Collapse: GraphCollapseResources},
+			want: `graph LR
+  n0["acme.org/app@1.0.0"]
+  n1[["frontend"]]
+  n2["acme.org/lib@2.0.0"]
+  n3[["backend"]]
+  n4["acme.org/base@3.0.0"]
+  n5[["os"]]
+  n0 --> n1
+  n0 --> n2
+  n2 --> n3
+  n2 --> n4
+  n4 --> n5
+`,
+		},
+		{
+			// zlib is drawn once for both images, openssl's dependency on it dashed
+			name: "full dot",
+			opts: GraphOptions{Format: GraphFormatDOT, Collapse: GraphCollapseFull},
+			want: `digraph "acme.org/app@1.0.0" {
+  rankdir=LR;
+  node [fontname="Helvetica"];
+  n0 [label="acme.org/app\n1.0.0", shape=box, style="rounded,bold"];
+  n1 [label="frontend", shape=component];
+  n2 [label="zlib\n1.3", shape=ellipse];
+  n3 [label="openssl\n3.3", shape=ellipse];
+  n4 [label="acme.org/lib\n2.0.0", shape=box, style="rounded,bold"];
+  n5 [label="backend", shape=component];
+  n6 [label="acme.org/base\n3.0.0", shape=box, style="rounded,bold"];
+  n7 [label="os", shape=component];
+  n8 [label="musl\n1.2.5", shape=ellipse];
+  n0 -> n1;
+  n1 -> n2;
+  n1 -> n3;
+  n0 -> n4;
+  n4 -> n5;
+  n5 -> n2;
+  n4 -> n6;
+  n6 -> n7;
+  n7 -> n8;
+  n3 -> n2 [style=dashed];
+}
+`,
+		},
+		{
+			name: "full mermaid",
+			opts: GraphOptions{Format: GraphFormatMermaid, Collapse: GraphCollapseFull},
+			want: `graph LR
+  n0["acme.org/app@1.0.0"]
+  n1[["frontend"]]
+  n2("zlib@1.3")
+  n3("openssl@3.3")
+  n4["acme.org/lib@2.0.0"]
+  n5[["backend"]]
+  n6["acme.org/base@3.0.0"]
+  n7[["os"]]
+  n8("musl@1.2.5")
+  n0 --> n1
+  n1 --> n2
+  n1 --> n3
+  n0 --> n4
+  n4 --> n5
+  n5 --> n2
+  n4 --> n6
+  n6 --> n7
+  n7 --> n8
+  n3 -.-> n2
+`,
+		},
+		{
+			// acme.org/base is two component levels below the root
+			name: "depth dot",
+			opts: GraphOptions{Format: GraphFormatDOT, MaxDepth: 1},
+			want: `digraph "acme.org/app@1.0.0" {
+  rankdir=LR;
+  node [fontname="Helvetica"];
+  n0 [label="acme.org/app\n1.0.0", shape=box, style="rounded,bold"];
+  n1 [label="frontend", shape=component];
+  n2 [label="acme.org/lib\n2.0.0", shape=box, style="rounded,bold"];
+  n3 [label="backend", shape=component];
+  n0 -> n1;
+  n0 -> n2;
+  n2 -> n3;
+}
+`,
+		},
+		{
+			name: "depth mermaid",
+			opts: GraphOptions{Format: GraphFormatMermaid, Collapse: GraphCollapseFull, MaxDepth: 1},
+			want: `graph LR
+  n0["acme.org/app@1.0.0"]
+  n1[["frontend"]]
+  n2("zlib@1.3")
+  n3("openssl@3.3")
+  n4["acme.org/lib@2.0.0"]
+  n5[["backend"]]
+  n0 --> n1
+  n1 --> n2
+  n1 --> n3
+  n0 --> n4
+  n4 --> n5
+  n5 --> n2
+  n3 -.-> n2
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := RenderGraph(graphBOM(), &b, tt.opts); err != nil {
+				t.Fatalf("RenderGraph: %v", err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("graph =\n%s\nwant\n%s", b.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+// InventoryFormat selects the delimiter of an inventory export.
+type InventoryFormat string
+
+const (
+	InventoryFormatCSV InventoryFormat = "csv"
+	InventoryFormatTSV InventoryFormat = "tsv"
+)
+
+// InventoryColumns lists the columns an inventory can have, in their default order.
+var InventoryColumns = []string{
+	"name", "version", "purl", "licenses", "type",
+	"component", "component-version", "resource", "image", "path", "bom-ref",
+}
+
+// DefaultInventoryColumns are written when no columns are selected.
+var DefaultInventoryColumns = []string{
+	"name", "version", "purl", "licenses", "component", "component-version", "resource", "image",
+}
+
+// InventoryRow is one package of a merged BOM with the OCM component version and resource it
+// was found in.
+type InventoryRow struct {
+	Name             string
+	Version          string
+	PURL             string
+	Licenses         string
+	Type             string
+	Component        string
+	ComponentVersion string
+	Resource         string
+	Image            string
+	// Path is the chain of component versions from the root, e.g. "root@1 > child@2".
+	Path   string
+	BOMRef string
+}
+
+// Value returns the value of a column.
+func (r InventoryRow) Value(column string) string {
+	switch column {
+	case "name":
+		return r.Name
+	case "version":
+		return r.Version
+	case "purl":
+		return r.PURL
+	case "licenses":
+		return r.Licenses
+	case "type":
+		return r.Type
+	case "component":
+		return r.Component
+	case "component-version":
+		return r.ComponentVersion
+	case "resource":
+		return r.Resource
+	case "image":
+		return r.Image
+	case "path":
+		return r.Path
+	case "bom-ref":
+		return r.BOMRef
+	default:
+		return ""
+	}
+}
+
+// ParseInventoryColumns parses a comma separated column list; an empty list selects
+// DefaultInventoryColumns.
+func ParseInventoryColumns(spec string) ([]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return DefaultInventoryColumns, nil
+	}
+	var columns []string
+	for _, column := range strings.Split(spec, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		known := false
+		for _, c := range InventoryColumns {
+			if c == column {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown inventory column %q (available: %s)", column, strings.Join(InventoryColumns, ", "))
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// BuildInventory lists every package of a merged BOM, following the OCM tree so each row can be
+// traced back to the component version and resource that contains the package. A package found
+// in several resources gets one row per resource.
+func BuildInventory(bom *cyclonedx.BOM) ([]InventoryRow, error) {
+	tree, err := BuildOCMTree(bom)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []InventoryRow
+	tree.Walk(func(node *OCMNode) bool {
+		if node.Kind != OCMNodePackage {
+			return true
+		}
+		comp := node.Component
+		row := InventoryRow{
+			Name:     comp.Name,
+			Version:  comp.Version,
+			PURL:     comp.PackageURL,
+			Licenses: spdxLicenseExpression(comp.Licenses),
+			Type:     string(comp.Type),
+			BOMRef:   comp.BOMRef,
+		}
+		if comp.Group != "" {
+			row.Name = comp.Group + "/" + comp.Name
+		}
+		if row.Licenses == spdxNoAssertion {
+			row.Licenses = ""
+		}
+		if owner := node.OwningComponent(); owner != nil {
+			row.Component = owner.Component.Name
+			row.ComponentVersion = owner.Component.Version
+			row.Path = componentPath(owner)
+		}
+		if resource := node.OwningResource(); resource != nil {
+			row.Resource = ocmResourceName(resource)
+			row.Image = resourceProperty(resource.Component, "imageReference")
+			if row.Image == "" {
+				row.Image = resource.Label()
+			}
+		}
+		rows = append(rows, row)
+		return true
+	})
+	return rows, nil
+}
+
+// WriteInventory writes the rows with a header line as CSV or TSV. Cells a spreadsheet would
+// evaluate as formula are escaped, as package names and versions come from scanned images.
+func WriteInventory(rows []InventoryRow, w io.Writer, format InventoryFormat, columns []string) error {
+	writer := csv.NewWriter(w)
+	switch format {
+	case InventoryFormatCSV, "":
+	case InventoryFormatTSV:
+		writer.Comma = '\t'
+	default:
+		return fmt.Errorf("unsupported inventory format: %s", format)
+	}
+	if len(columns) == 0 {
+		columns = DefaultInventoryColumns
+	}
+
+	if err := writer.Write(columns); err != nil {
+		return fmt.Errorf("failed to write inventory header: %w", err)
+	}
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, column := range columns {
+			record[i] = escapeInventoryCell(row.Value(column))
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write inventory row: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// escapeInventoryCell prefixes a cell starting with a formula character with "'", so spreadsheets
+// show it as text.
+func escapeInventoryCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// componentPath returns the component versions from the root to node, e.g. "root@1 > child@2".
+func componentPath(node *OCMNode) string {
+	var labels []string
+	for n := node; n != nil; n = n.Parent {
+		if n.Kind == OCMNodeComponent {
+			labels = append([]string{n.Label()}, labels...)
+		}
+	}
+	return strings.Join(labels, " > ")
+}
+
+// ocmResourceName returns the OCM resource name of a resource node, recorded as property or in
+// its OCM bom-ref.
+func ocmResourceName(node *OCMNode) string {
+	if name := resourceProperty(node.Component, "name"); name != "" {
+		return name
+	}
+	if !isOCMBOMRef(node.Ref()) {
+		return ""
+	}
+	_, resource, found := strings.Cut(node.Ref(), "/resource/")
+	if !found {
+		return ""
+	}
+	// Drop the extra identity
+	if i := strings.Index(resource, "["); i >= 0 {
+		resource = resource[:i]
+	}
+	return resource
+}
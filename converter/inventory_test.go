@@ -0,0 +1,77 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+func TestBuildInventoryTracesPackagesToResources(t *testing.T) {
+	appRef := OCMComponentBOMRef("acme.org/app", "1.0.0")
+	libRef := OCMComponentBOMRef("acme.org/lib", "1.0.0")
+	appResource := OCMResourceBOMRef("acme.org/app", "1.0.0", "image", nil)
+	libResource := OCMResourceBOMRef("acme.org/lib", "1.0.0", "image", nil)
+	own := resourceBOM(cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/app", Version: "1.0.0", BOMRef: appRef},
+		appResource, appResource+"/pkg:apk/alpine/zlib@1.3")
+	child := resourceBOM(cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/lib", Version: "1.0.0", BOMRef: libRef},
+		libResource, libResource+"/pkg:apk/alpine/zlib@1.3")
+	subject := &cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/app", Version: "1.0.0", BOMRef: appRef}
+	merged, err := HierarchicalMerge([]cyclonedx.BOM{own, child}, subject)
+	if err != nil {
+		t.Fatalf("HierarchicalMerge: %v", err)
+	}
+
+	rows, err := BuildInventory(merged)
+	if err != nil {
+		t.Fatalf("BuildInventory: %v", err)
+	}
+	// The package found in both images gets a row for each
+	var got []string
+	for _, row := range rows {
+		got = append(got, row.Name+"@"+row.Version+" in "+row.Resource+" of "+row.Path)
+	}
+	want := []string{
+		"zlib@1.3 in image of acme.org/app@1.0.0",
+		"zlib@1.3 in image of acme.org/app@1.0.0 > acme.org/lib@1.0.0",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("rows =\n%q\nwant\n%q", got, want)
+	}
+	for _, row := range rows {
+		if row.PURL != "pkg:apk/alpine/zlib@1.3" || row.Component == "" || row.ComponentVersion != "1.0.0" {
+			t.Errorf("row = %+v, want the purl and owning component version", row)
+		}
+	}
+}
+
+func TestWriteInventoryEscapesFormulas(t *testing.T) {
+	rows := []InventoryRow{
+		{Name: "=HYPERLINK(\"http://evil.example\")", Version: "-1", PURL: "+cmd", Licenses: "@SUM(A1)"},
+		{Name: "zlib", Version: "1.3", PURL: "pkg:apk/alpine/zlib@1.3", Licenses: "Zlib"},
+	}
+	for _, tt := range []struct {
+		format InventoryFormat
+		want   string
+	}{
+		{InventoryFormatCSV, "name,version,purl,licenses\n\"'=HYPERLINK(\"\"http://evil.example\"\")\",'-1,'+cmd,'@SUM(A1)\nzlib,1.3,pkg:apk/alpine/zlib@1.3,Zlib\n"},
+		{InventoryFormatTSV, "name\tversion\tpurl\tlicenses\n\"'=HYPERLINK(\"\"http://evil.example\"\")\"\t'-1\t'+cmd\t'@SUM(A1)\nzlib\t1.3\tpkg:apk/alpine/zlib@1.3\tZlib\n"},
+	} {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteInventory(rows, &buf, tt.format, []string{"name", "version", "purl", "licenses"}); err != nil {
+				t.Fatalf("WriteInventory: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("inventory =\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
+)
+
+// labelPropertyPrefix starts the names of the properties OCM labels are mapped to:
+// "ocm:label:<name>" holds the value, "ocm:label:<name>:version" and "ocm:label:<name>:signing"
+// the label version and signing flag if set.
+const labelPropertyPrefix = "ocm:label:"
+
+// LabelFilter selects the OCM labels exported as properties by name. Patterns use path.Match
+// syntax, e.g. "acme.org/*".
+type LabelFilter struct {
+	// Allow lists the labels to export; empty exports all labels not denied.
+	Allow []string
+	// Deny lists labels never to export; it takes precedence over Allow.
+	Deny []string
+}
+
+// NewLabelFilter creates a LabelFilter and validates its patterns.
+func NewLabelFilter(allow, deny []string) (*LabelFilter, error) {
+	for _, pattern := range append(append([]string{}, allow...), deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid label pattern %q: %w", pattern, err)
+		}
+	}
+	return &LabelFilter{Allow: allow, Deny: deny}, nil
+}
+
+// Includes reports whether the label with the given name is exported. A nil filter exports all
+// labels.
+func (f *LabelFilter) Includes(name string) bool {
+	if f == nil {
+		return true
+	}
+	for _, pattern := range f.Deny {
+		if ok, _ := path.Match(pattern, name); ok {
+			return false
+		}
+	}
+	if len(f.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range f.Allow {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// LabelProperties maps the OCM labels accepted by filter to properties, in descriptor order.
+func LabelProperties(labels []runtime.Label, filter *LabelFilter) []cyclonedx.Property {
+	var props []cyclonedx.Property
+	for _, label := range labels {
+		if !filter.Includes(label.Name) {
+			continue
+		}
+		value, err := canonicalLabelValue(label.Value)
+		if err != nil {
+			log.Printf("Warning: label %s has an invalid JSON value, exporting it as is: %v", label.Name, err)
+			value = string(label.Value)
+		}
+		name := labelPropertyPrefix + label.Name
+		props = append(props, cyclonedx.Property{Name: name, Value: value})
+		if label.Version != "" {
+			props = append(props, cyclonedx.Property{Name: name + ":version", Value: label.Version})
+		}
+		if label.Signing {
+			props = append(props, cyclonedx.Property{Name: name + ":signing", Value: "true"})
+		}
+	}
+	return props
+}
+
+// canonicalLabelValue returns a label value as property value: strings as they are, any other
+// JSON value re-encoded compactly with sorted object keys, so equal values always serialize the
+// same way.
+func canonicalLabelValue(raw json.RawMessage) (string, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return "", nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return "", err
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// addLabelProperties adds the label properties of a resource to the scanned artifact of its SBOM.
+func (c *CLIConverter) addLabelProperties(bom *cyclonedx.BOM, labels []runtime.Label) {
+	if bom == nil || bom.Metadata == nil || bom.Metadata.Component == nil {
+		return
+	}
+	props := LabelProperties(labels, c.LabelFilter)
+	if len(props) == 0 {
+		return
+	}
+	node := bom.Metadata.Component
+	if node.Properties == nil {
+		node.Properties = &[]cyclonedx.Property{}
+	}
+	*node.Properties = append(*node.Properties, props...)
+}
@@ -0,0 +1,72 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
+)
+
+func TestLabelFilterIncludes(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter *LabelFilter
+		label  string
+		want   bool
+	}{
+		{name: "nil filter", label: "acme.org/team", want: true},
+		{name: "empty filter", filter: &LabelFilter{}, label: "acme.org/team", want: true},
+		{name: "allowed by glob", filter: &LabelFilter{Allow: []string{"acme.org/*"}}, label: "acme.org/team", want: true},
+		{name: "not allowed", filter: &LabelFilter{Allow: []string{"acme.org/*"}}, label: "other.org/team", want: false},
+		{name: "glob does not cross slashes", filter: &LabelFilter{Allow: []string{"acme.org/*"}}, label: "acme.org/team/lead", want: false},
+		{name: "denied", filter: &LabelFilter{Deny: []string{"*/secret*"}}, label: "acme.org/secret-key", want: false},
+		{name: "deny over allow", filter: &LabelFilter{Allow: []string{"acme.org/*"}, Deny: []string{"acme.org/internal"}}, label: "acme.org/internal", want: false},
+		{name: "allowed beside deny", filter: &LabelFilter{Allow: []string{"acme.org/*"}, Deny: []string{"acme.org/internal"}}, label: "acme.org/team", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Includes(tt.label); got != tt.want {
+				t.Errorf("Includes(%q) = %v, want %v", tt.label, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewLabelFilter([]string{"acme.org/["}, nil); err == nil {
+		t.Errorf("invalid pattern accepted")
+	}
+}
+
+func TestLabelProperties(t *testing.T) {
+	labels := []runtime.Label{
+		{Name: "acme.org/team", Value: json.RawMessage(`"platform"`), Version: "v1", Signing: true},
+		{Name: "acme.org/owners", Value: json.RawMessage(`{"z": ["b", "a"], "a": {"y": 1.50, "x": "<&>"}}`)},
+		{Name: "acme.org/internal", Value: json.RawMessage(`true`)},
+		{Name: "other.org/ignored", Value: json.RawMessage(`1`)},
+	}
+	filter, err := NewLabelFilter([]string{"acme.org/*"}, []string{"acme.org/internal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, p := range LabelProperties(labels, filter) {
+		got = append(got, p.Name+"="+p.Value)
+	}
+	// Strings are kept as they are, structured values are compact JSON with sorted keys
+	want := []string{
+		"ocm:label:acme.org/team=platform",
+		"ocm:label:acme.org/team:version=v1",
+		"ocm:label:acme.org/team:signing=true",
+		`ocm:label:acme.org/owners={"a":{"x":"<&>","y":1.50},"z":["b","a"]}`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("properties =\n%q\nwant\n%q", got, want)
+	}
+}
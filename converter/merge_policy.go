@@ -0,0 +1,408 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+// MergeStrategy defines how a component field is resolved when two inputs describe the same package differently.
+type MergeStrategy string
+
+const (
+	// StrategyKeepFirst keeps the value of the first input (the historic merge behaviour).
+	StrategyKeepFirst MergeStrategy = "keep-first"
+	// StrategyUnion combines the values of all inputs. Only valid for list fields (licenses, hashes).
+	StrategyUnion MergeStrategy = "union"
+	// StrategyPreferNewest keeps the value of the input with the most recent metadata timestamp.
+	StrategyPreferNewest MergeStrategy = "prefer-newest"
+	// StrategyFail aborts the merge on the first conflict.
+	StrategyFail MergeStrategy = "fail"
+)
+
+// MergeField names a component field that is subject to a merge strategy.
+type MergeField string
+
+const (
+	MergeFieldLicenses MergeField = "licenses"
+	MergeFieldHashes   MergeField = "hashes"
+	MergeFieldCPE      MergeField = "cpe"
+	MergeFieldSupplier MergeField = "supplier"
+)
+
+// MergePolicy holds the field-level strategies applied when components share an identity.
+type MergePolicy struct {
+	Licenses MergeStrategy
+	Hashes   MergeStrategy
+	CPE      MergeStrategy
+	Supplier MergeStrategy
+}
+
+// ErrMergeConflict is returned when a field with StrategyFail differs between inputs.
+var ErrMergeConflict = errors.New("conflicting component metadata")
+
+// DefaultMergePolicy returns the policy used when only --merge-policy overrides are given:
+// licenses and hashes are combined, single-valued fields keep the first value.
+func DefaultMergePolicy() MergePolicy {
+	return MergePolicy{
+		Licenses: StrategyUnion,
+		Hashes:   StrategyUnion,
+		CPE:      StrategyKeepFirst,
+		Supplier: StrategyKeepFirst,
+	}
+}
+
+// ParseMergePolicy parses a comma separated list of field=strategy pairs (e.g. "licenses=union,cpe=fail")
+// on top of DefaultMergePolicy. The field "*" sets all fields at once.
+func ParseMergePolicy(spec string) (MergePolicy, error) {
+	policy := DefaultMergePolicy()
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		field, strategy, ok := strings.Cut(pair, "=")
+		if !ok {
+			return policy, fmt.Errorf("invalid merge policy entry %q, expected field=strategy", pair)
+		}
+		field = strings.ToLower(strings.TrimSpace(field))
+		s := MergeStrategy(strings.ToLower(strings.TrimSpace(strategy)))
+		switch field {
+		case "*":
+			policy = MergePolicy{Licenses: s, Hashes: s, CPE: s, Supplier: s}
+		case string(MergeFieldLicenses):
+			policy.Licenses = s
+		case string(MergeFieldHashes):
+			policy.Hashes = s
+		case string(MergeFieldCPE):
+			policy.CPE = s
+		case string(MergeFieldSupplier):
+			policy.Supplier = s
+		default:
+			return policy, fmt.Errorf("unknown merge policy field %q. Supported: licenses, hashes, cpe, supplier", field)
+		}
+	}
+	return policy, policy.Validate()
+}
+
+// String returns the policy in the field=strategy form accepted by ParseMergePolicy.
+func (p MergePolicy) String() string {
+	return fmt.Sprintf("%s=%s,%s=%s,%s=%s,%s=%s",
+		MergeFieldLicenses, p.Licenses, MergeFieldHashes, p.Hashes, MergeFieldCPE, p.CPE, MergeFieldSupplier, p.Supplier)
+}
+
+// Validate checks that every field has a strategy it supports.
+func (p MergePolicy) Validate() error {
+	checks := []struct {
+		field    MergeField
+		strategy MergeStrategy
+		list     bool
+	}{
+		{MergeFieldLicenses, p.Licenses, true},
+		{MergeFieldHashes, p.Hashes, true},
+		{MergeFieldCPE, p.CPE, false},
+		{MergeFieldSupplier, p.Supplier, false},
+	}
+	for _, c := range checks {
+		switch c.strategy {
+		case StrategyKeepFirst, StrategyPreferNewest, StrategyFail:
+		case StrategyUnion:
+			if !c.list {
+				return fmt.Errorf("merge strategy %q is not supported for %s", c.strategy, c.field)
+			}
+		default:
+			return fmt.Errorf("unknown merge strategy %q for %s. Supported: keep-first, union, prefer-newest, fail", c.strategy, c.field)
+		}
+	}
+	return nil
+}
+
+// MergeConflict describes one field on which two inputs disagreed.
+type MergeConflict struct {
+	Component  string        `json:"component"`
+	BOMRef     string        `json:"bomRef,omitempty"`
+	Field      MergeField    `json:"field"`
+	Values     []string      `json:"values"`
+	Sources    []string      `json:"sources"`
+	Resolution MergeStrategy `json:"resolution"`
+}
+
+// ConflictReport collects all conflicts found while merging.
+type ConflictReport struct {
+	Conflicts []MergeConflict `json:"conflicts"`
+}
+
+// Add appends conflicts to the report.
+func (r *ConflictReport) Add(conflicts ...MergeConflict) {
+	if r == nil {
+		return
+	}
+	r.Conflicts = append(r.Conflicts, conflicts...)
+}
+
+// WriteFile writes the report as indented JSON.
+func (r *ConflictReport) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal conflict report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write conflict report to %s: %w", path, err)
+	}
+	return nil
+}
+
+// mergeSource identifies the input a component came from.
+type mergeSource struct {
+	Name      string
+	Timestamp time.Time
+}
+
+// bomMergeSource derives the merge source from a BOM's metadata.
+func bomMergeSource(bom cyclonedx.BOM) mergeSource {
+	src := mergeSource{Name: bom.SerialNumber}
+	if bom.Metadata == nil {
+		return src
+	}
+	if bom.Metadata.Component != nil {
+		src.Name = componentBOMRefNamespace(bom.Metadata.Component)
+	}
+	if ts, err := time.Parse(time.RFC3339, bom.Metadata.Timestamp); err == nil {
+		src.Timestamp = ts
+	}
+	return src
+}
+
+// apply resolves the differences between an existing component and an incoming one with the same identity.
+// The existing component is updated in place; every difference is recorded in the report.
+func (p MergePolicy) apply(existing *cyclonedx.Component, existingSrc mergeSource, incoming cyclonedx.Component, incomingSrc mergeSource, report *ConflictReport) error {
+	newer := incomingSrc.Timestamp.After(existingSrc.Timestamp)
+	conflict := func(field MergeField, a, b string, strategy MergeStrategy) error {
+		c := MergeConflict{
+			Component:  componentIdentityName(*existing),
+			BOMRef:     existing.BOMRef,
+			Field:      field,
+			Values:     []string{a, b},
+			Sources:    []string{existingSrc.Name, incomingSrc.Name},
+			Resolution: strategy,
+		}
+		report.Add(c)
+		if strategy == StrategyFail {
+			return fmt.Errorf("%w: %s of %s differs between %s (%s) and %s (%s)",
+				ErrMergeConflict, field, c.Component, existingSrc.Name, a, incomingSrc.Name, b)
+		}
+		return nil
+	}
+
+	// Licenses
+	if a, b := licenseValues(existing.Licenses), licenseValues(incoming.Licenses); len(b) > 0 {
+		if len(a) == 0 {
+			existing.Licenses = incoming.Licenses
+		} else if !slices.Equal(a, b) {
+			if err := conflict(MergeFieldLicenses, strings.Join(a, " | "), strings.Join(b, " | "), p.Licenses); err != nil {
+				return err
+			}
+			switch {
+			case p.Licenses == StrategyUnion:
+				licenses := *existing.Licenses
+				for _, l := range *incoming.Licenses {
+					if !slices.Contains(a, licenseValue(l)) {
+						licenses = append(licenses, l)
+					}
+				}
+				existing.Licenses = &licenses
+			case p.Licenses == StrategyPreferNewest && newer:
+				existing.Licenses = incoming.Licenses
+			}
+		}
+	}
+
+	// Hashes
+	if a, b := hashValues(existing.Hashes), hashValues(incoming.Hashes); len(b) > 0 {
+		if len(a) == 0 {
+			existing.Hashes = incoming.Hashes
+		} else if !slices.Equal(a, b) {
+			if err := conflict(MergeFieldHashes, strings.Join(a, " | "), strings.Join(b, " | "), p.Hashes); err != nil {
+				return err
+			}
+			switch {
+			case p.Hashes == StrategyUnion:
+				hashes := *existing.Hashes
+				for _, h := range *incoming.Hashes {
+					if !slices.Contains(a, hashValue(h)) {
+						hashes = append(hashes, h)
+					}
+				}
+				existing.Hashes = &hashes
+			case p.Hashes == StrategyPreferNewest && newer:
+				existing.Hashes = incoming.Hashes
+			}
+		}
+	}
+
+	// CPE
+	if incoming.CPE != "" {
+		if existing.CPE == "" {
+			existing.CPE = incoming.CPE
+		} else if existing.CPE != incoming.CPE {
+			if err := conflict(MergeFieldCPE, existing.CPE, incoming.CPE, p.CPE); err != nil {
+				return err
+			}
+			if p.CPE == StrategyPreferNewest && newer {
+				existing.CPE = incoming.CPE
+			}
+		}
+	}
+
+	// Supplier
+	if b := supplierValue(incoming.Supplier); b != "" {
+		if a := supplierValue(existing.Supplier); a == "" {
+			existing.Supplier = incoming.Supplier
+		} else if a != b {
+			if err := conflict(MergeFieldSupplier, a, b, p.Supplier); err != nil {
+				return err
+			}
+			if p.Supplier == StrategyPreferNewest && newer {
+				existing.Supplier = incoming.Supplier
+			}
+		}
+	}
+
+	return nil
+}
+
+// componentIdentityName returns the purl of a component, falling back to name@version.
+func componentIdentityName(comp cyclonedx.Component) string {
+	if comp.PackageURL != "" {
+		return comp.PackageURL
+	}
+	return componentBOMRefNamespace(&comp)
+}
+
+// licenseValue returns the comparable value of a license choice.
+func licenseValue(l cyclonedx.LicenseChoice) string {
+	if l.Expression != "" {
+		return l.Expression
+	}
+	if l.License != nil {
+		if l.License.ID != "" {
+			return l.License.ID
+		}
+		return l.License.Name
+	}
+	return ""
+}
+
+// licenseValues returns the sorted license values of a component.
+func licenseValues(licenses *cyclonedx.Licenses) []string {
+	if licenses == nil {
+		return nil
+	}
+	var values []string
+	for _, l := range *licenses {
+		if v := licenseValue(l); v != "" {
+			values = append(values, v)
+		}
+	}
+	sort.Strings(values)
+	return slices.Compact(values)
+}
+
+// hashValue returns the comparable value of a hash.
+func hashValue(h cyclonedx.Hash) string {
+	return fmt.Sprintf("%s:%s", h.Algorithm, strings.ToLower(h.Value))
+}
+
+// hashValues returns the sorted hash values of a component.
+func hashValues(hashes *[]cyclonedx.Hash) []string {
+	if hashes == nil {
+		return nil
+	}
+	var values []string
+	for _, h := range *hashes {
+		values = append(values, hashValue(h))
+	}
+	sort.Strings(values)
+	return slices.Compact(values)
+}
+
+// supplierValue returns the comparable value of a supplier.
+func supplierValue(supplier *cyclonedx.OrganizationalEntity) string {
+	if supplier == nil {
+		return ""
+	}
+	value := supplier.Name
+	if supplier.URL != nil && len(*supplier.URL) > 0 {
+		value = fmt.Sprintf("%s (%s)", value, strings.Join(*supplier.URL, ", "))
+	}
+	return value
+}
+
+// componentIndex finds components that share an identity while merging and applies the merge policy.
+// Without a policy, components are only matched by bom-ref and the first one wins.
+type componentIndex struct {
+	components *[]cyclonedx.Component
+	sources    []mergeSource
+	byRef      map[string]int
+	byIdentity map[string]int
+	policy     *MergePolicy
+	report     *ConflictReport
+}
+
+func newComponentIndex(components *[]cyclonedx.Component, policy *MergePolicy, report *ConflictReport) *componentIndex {
+	return &componentIndex{
+		components: components,
+		byRef:      make(map[string]int),
+		byIdentity: make(map[string]int),
+		policy:     policy,
+		report:     report,
+	}
+}
+
+// add merges a component into the index. It returns the bom-ref under which the component is stored.
+func (ci *componentIndex) add(comp cyclonedx.Component, src mergeSource) (string, error) {
+	idx, found := ci.byRef[comp.BOMRef]
+	if !found && ci.policy != nil {
+		if key := packageIdentity(comp); key != "" {
+			idx, found = ci.byIdentity[key]
+		}
+	}
+	if !found {
+		idx = len(*ci.components)
+		*ci.components = append(*ci.components, comp)
+		ci.sources = append(ci.sources, src)
+		if comp.BOMRef != "" {
+			ci.byRef[comp.BOMRef] = idx
+		}
+		if key := packageIdentity(comp); key != "" {
+			if _, ok := ci.byIdentity[key]; !ok {
+				ci.byIdentity[key] = idx
+			}
+		}
+		return comp.BOMRef, nil
+	}
+
+	existing := &(*ci.components)[idx]
+	if ci.policy != nil {
+		if err := ci.policy.apply(existing, ci.sources[idx], comp, src, ci.report); err != nil {
+			return "", err
+		}
+		if src.Timestamp.After(ci.sources[idx].Timestamp) {
+			ci.sources[idx] = src
+		}
+	}
+	return existing.BOMRef, nil
+}
@@ -0,0 +1,177 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+// ExternalMergeProtocolVersion is the version of the stdin/stdout protocol spoken with external mergers.
+const ExternalMergeProtocolVersion = "1"
+
+// ExternalMergeRequest is written as a single JSON document to the stdin of an external merger.
+type ExternalMergeRequest struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Component       ExternalMergeComponent `json:"component"`
+	MergeMode       string                 `json:"mergeMode"`
+	BOMs            []json.RawMessage      `json:"boms"`
+}
+
+// ExternalMergeComponent identifies the component the merged SBOM describes.
+type ExternalMergeComponent struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// BOMRef is the bom-ref the merged component should use, if a bom-ref scheme requires one.
+	BOMRef string `json:"bomRef,omitempty"`
+}
+
+// ExternalMergeResponse is the JSON document an external merger writes to stdout.
+// Either BOM holds the merged CycloneDX JSON SBOM or Error describes why merging failed.
+type ExternalMergeResponse struct {
+	BOM   json.RawMessage `json:"bom,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+// ExternalCommandMerger delegates merging to an arbitrary executable. The command receives an
+// ExternalMergeRequest on stdin and must answer with an ExternalMergeResponse on stdout. A non-zero
+// exit code or a non-empty error field fails the merge; stderr is passed through to the log.
+// See docs/merge-protocol.md for the full protocol description.
+type ExternalCommandMerger struct {
+	Command string
+	Args    []string
+}
+
+// NewExternalCommandMerger creates a merger that runs the given command.
+func NewExternalCommandMerger(command string, args ...string) *ExternalCommandMerger {
+	return &ExternalCommandMerger{Command: command, Args: args}
+}
+
+// Merge implements Merger.
+func (m *ExternalCommandMerger) Merge(req MergeRequest) error {
+	request := ExternalMergeRequest{
+		ProtocolVersion: ExternalMergeProtocolVersion,
+		Component:       ExternalMergeComponent{Name: req.ComponentName, Version: req.ComponentVersion, BOMRef: req.SubjectBOMRef},
+		MergeMode:       string(req.MergeMode),
+	}
+	for _, path := range req.InputPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read sbom file %s: %w", path, err)
+		}
+		if !json.Valid(data) {
+			return fmt.Errorf("sbom file %s is not valid JSON", path)
+		}
+		request.BOMs = append(request.BOMs, json.RawMessage(data))
+	}
+	input, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode merge request: %w", err)
+	}
+
+	cmd := exec.Command(m.Command, m.Args...)
+	cmd.Dir = req.WorkDir
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	log.Printf("Executing external merge: %s %s", m.Command, strings.Join(m.Args, " "))
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("command '%s %s' failed: %w\nStderr:\n%s", m.Command, strings.Join(m.Args, " "), err, stderr.String())
+	}
+	if stderr.Len() > 0 {
+		log.Printf("External merger stderr: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	var response ExternalMergeResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return fmt.Errorf("failed to decode response of external merger: %w", err)
+	}
+	if response.Error != "" {
+		return fmt.Errorf("external merger reported an error: %s", response.Error)
+	}
+	if len(response.BOM) == 0 {
+		return fmt.Errorf("external merger returned no bom")
+	}
+
+	// Decode to make sure the result is a usable CycloneDX SBOM before writing it
+	var bom cyclonedx.BOM
+	if err := json.Unmarshal(response.BOM, &bom); err != nil {
+		return fmt.Errorf("external merger returned an invalid bom: %w", err)
+	}
+	if bom.Metadata == nil || bom.Metadata.Component == nil {
+		return fmt.Errorf("merged BOM is invalid: missing metadata component")
+	}
+	return writeMergedBOM(&bom, req.OutputPath)
+}
+
+// splitCommandLine splits a command line into arguments like a POSIX shell, without expansions:
+// arguments are separated by whitespace, single quotes keep everything literally, double quotes
+// keep everything but backslash escapes of ", \, $ and `, and a backslash outside of quotes
+// escapes the next character.
+func splitCommandLine(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case r == '\'':
+			inArg = true
+			end := slices.Index(runes[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			current.WriteString(string(runes[i+1 : i+1+end]))
+			i += end + 1
+		case r == '"':
+			inArg = true
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated double quote")
+				}
+				if runes[i] == '"' {
+					break
+				}
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`", runes[i+1]) {
+					i++
+				}
+				current.WriteRune(runes[i])
+			}
+		case r == '\\':
+			inArg = true
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			i++
+			current.WriteRune(runes[i])
+		default:
+			inArg = true
+			current.WriteRune(r)
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
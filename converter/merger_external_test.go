@@ -0,0 +1,151 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+// stubMerger writes a shell script that saves its stdin to request.json next to it, prints
+// response to stdout and exits with exitCode.
+func stubMerger(t *testing.T, response string, exitCode int) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("stub merger is a shell script")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "response.json"), []byte(response), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\n" +
+		"cat > \"$(dirname \"$0\")/request.json\"\n" +
+		"cat \"$(dirname \"$0\")/response.json\"\n" +
+		"echo 'stub stderr' >&2\n" +
+		"exit " + strconv.Itoa(exitCode) + "\n"
+	path := filepath.Join(dir, "merger.sh")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// externalMergeRequest writes one input SBOM and returns a request to merge it.
+func externalMergeRequest(t *testing.T) MergeRequest {
+	t.Helper()
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.json")
+	if err := os.WriteFile(input, []byte(`{"bomFormat":"CycloneDX","specVersion":"1.6","version":1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return MergeRequest{
+		ComponentName:    "acme.org/app",
+		ComponentVersion: "1.0.0",
+		InputPaths:       []string{input},
+		OutputPath:       filepath.Join(dir, "merged.json"),
+		WorkDir:          dir,
+		MergeMode:        MergeModeHierarchical,
+	}
+}
+
+func TestExternalCommandMergerWritesReturnedBOM(t *testing.T) {
+	stub := stubMerger(t, `{"bom":{"bomFormat":"CycloneDX","specVersion":"1.6","version":1,"metadata":{"component":{"type":"application","name":"acme.org/app","version":"1.0.0"}}}}`, 0)
+	req := externalMergeRequest(t)
+
+	if err := NewExternalCommandMerger(stub).Merge(req); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+
+	data, err := os.ReadFile(req.OutputPath)
+	if err != nil {
+		t.Fatalf("merged SBOM not written: %v", err)
+	}
+	var bom cyclonedx.BOM
+	if err := json.Unmarshal(data, &bom); err != nil {
+		t.Fatalf("merged SBOM is not valid JSON: %v", err)
+	}
+	if bom.Metadata == nil || bom.Metadata.Component == nil || bom.Metadata.Component.Name != "acme.org/app" {
+		t.Errorf("merged SBOM metadata = %+v, want component acme.org/app", bom.Metadata)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(filepath.Dir(stub), "request.json"))
+	if err != nil {
+		t.Fatalf("stub did not receive a request: %v", err)
+	}
+	var sent ExternalMergeRequest
+	if err := json.Unmarshal(raw, &sent); err != nil {
+		t.Fatalf("request is not valid JSON: %v", err)
+	}
+	if sent.ProtocolVersion != ExternalMergeProtocolVersion || sent.Component.Name != "acme.org/app" ||
+		sent.MergeMode != string(MergeModeHierarchical) || len(sent.BOMs) != 1 {
+		t.Errorf("request = %+v", sent)
+	}
+}
+
+func TestExternalCommandMergerFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		exitCode int
+		wantErr  string
+	}{
+		{name: "error response", response: `{"error":"conflicting licenses"}`, wantErr: "conflicting licenses"},
+		{name: "non-zero exit", response: `{}`, exitCode: 3, wantErr: "stub stderr"},
+		{name: "invalid JSON", response: `not json`, wantErr: "failed to decode response"},
+		{name: "no bom", response: `{}`, wantErr: "returned no bom"},
+		{name: "bom without subject", response: `{"bom":{"bomFormat":"CycloneDX","specVersion":"1.6"}}`, wantErr: "missing metadata component"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := externalMergeRequest(t)
+			err := NewExternalCommandMerger(stubMerger(t, tt.response, tt.exitCode)).Merge(req)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Merge error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if _, statErr := os.Stat(req.OutputPath); statErr == nil {
+				t.Errorf("merged SBOM written despite failure")
+			}
+		})
+	}
+}
+
+func TestLookupMergerSplitsQuotedCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "exec:./merger --strict", want: []string{"./merger", "--strict"}},
+		{name: `exec:'/opt/my tools/merger' --label "release build"`, want: []string{"/opt/my tools/merger", "--label", "release build"}},
+		{name: `exec:/opt/my\ tools/merger --sep ''`, want: []string{"/opt/my tools/merger", "--sep", ""}},
+		{name: `exec:merger "say \"hi\" \n"`, want: []string{"merger", `say "hi" \n`}},
+	}
+	for _, tt := range tests {
+		merger, err := LookupMerger(tt.name, nil)
+		if err != nil {
+			t.Errorf("LookupMerger(%q): %v", tt.name, err)
+			continue
+		}
+		external := merger.(*ExternalCommandMerger)
+		if got := append([]string{external.Command}, external.Args...); !slices.Equal(got, tt.want) {
+			t.Errorf("LookupMerger(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	for _, name := range []string{"exec:", "exec:  ", "exec:'merger", `exec:"merger`, `exec:merger\`} {
+		if _, err := LookupMerger(name, nil); err == nil {
+			t.Errorf("LookupMerger(%q) succeeded, want an error", name)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+// OCMNodeKind classifies the nodes of a merged BOM.
+type OCMNodeKind string
+
+const (
+	// OCMNodeComponent is an OCM component version.
+	OCMNodeComponent OCMNodeKind = "component"
+	// OCMNodeResource is a scanned OCM resource, e.g. an OCI image.
+	OCMNodeResource OCMNodeKind = "resource"
+	// OCMNodePackage is a package found in a resource.
+	OCMNodePackage OCMNodeKind = "package"
+)
+
+// OCMNode is a node of the OCM tree encoded in a merged BOM: component versions contain
+// resources and other component versions, resources contain packages.
+type OCMNode struct {
+	Kind      OCMNodeKind
+	Component *cyclonedx.Component
+	Parent    *OCMNode
+	Children  []*OCMNode
+}
+
+// Ref returns the bom-ref of the node.
+func (n *OCMNode) Ref() string {
+	return n.Component.BOMRef
+}
+
+// Label returns "name@version", or the name if the node has no version.
+func (n *OCMNode) Label() string {
+	if n.Component.Version == "" {
+		return n.Component.Name
+	}
+	return n.Component.Name + "@" + n.Component.Version
+}
+
+// OwningComponent returns the closest OCM component version at or above the node.
+func (n *OCMNode) OwningComponent() *OCMNode {
+	for node := n; node != nil; node = node.Parent {
+		if node.Kind == OCMNodeComponent {
+			return node
+		}
+	}
+	return nil
+}
+
+// OwningResource returns the closest resource at or above the node, or nil.
+func (n *OCMNode) OwningResource() *OCMNode {
+	for node := n; node != nil; node = node.Parent {
+		switch node.Kind {
+		case OCMNodeResource:
+			return node
+		case OCMNodeComponent:
+			return nil
+		}
+	}
+	return nil
+}
+
+// Depth returns the number of component versions above the node.
+func (n *OCMNode) Depth() int {
+	depth := 0
+	for node := n.Parent; node != nil; node = node.Parent {
+		if node.Kind == OCMNodeComponent {
+			depth++
+		}
+	}
+	return depth
+}
+
+// ChildrenOfKind returns the direct children of the given kind.
+func (n *OCMNode) ChildrenOfKind(kind OCMNodeKind) []*OCMNode {
+	var result []*OCMNode
+	for _, child := range n.Children {
+		if child.Kind == kind {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+// Walk visits the node and its descendants depth first. Returning false skips the children.
+func (n *OCMNode) Walk(fn func(node *OCMNode) bool) {
+	if !fn(n) {
+		return
+	}
+	for _, child := range n.Children {
+		child.Walk(fn)
+	}
+}
+
+// Packages returns all packages below the node that do not belong to a nested component version.
+func (n *OCMNode) Packages() []*OCMNode {
+	var result []*OCMNode
+	for _, child := range n.Children {
+		child.Walk(func(node *OCMNode) bool {
+			switch node.Kind {
+			case OCMNodeComponent:
+				return false
+			case OCMNodePackage:
+				result = append(result, node)
+			}
+			return true
+		})
+	}
+	return result
+}
+
+// BuildOCMTree reconstructs the OCM tree from a merged BOM. Hierarchical merges encode the tree
+// by nesting components; flat merges link the same nodes through dependencies. Both are followed,
+// so the tree is the same for either merge mode.
+func BuildOCMTree(bom *cyclonedx.BOM) (*OCMNode, error) {
+	if bom == nil || bom.Metadata == nil || bom.Metadata.Component == nil {
+		return nil, fmt.Errorf("BOM has no metadata component")
+	}
+
+	index := make(map[string]*cyclonedx.Component)
+	var indexComponents func(components *[]cyclonedx.Component)
+	indexComponents = func(components *[]cyclonedx.Component) {
+		if components == nil {
+			return
+		}
+		for i := range *components {
+			comp := &(*components)[i]
+			if comp.BOMRef != "" {
+				if _, ok := index[comp.BOMRef]; !ok {
+					index[comp.BOMRef] = comp
+				}
+			}
+			indexComponents(comp.Components)
+		}
+	}
+	root := bom.Metadata.Component
+	if root.BOMRef != "" {
+		index[root.BOMRef] = root
+	}
+	indexComponents(root.Components)
+	indexComponents(bom.Components)
+
+	dependsOn := make(map[string][]string)
+	targets := make(map[string]bool)
+	if bom.Dependencies != nil {
+		for _, dep := range *bom.Dependencies {
+			if dep.Dependencies != nil {
+				dependsOn[dep.Ref] = append(dependsOn[dep.Ref], *dep.Dependencies...)
+				for _, target := range *dep.Dependencies {
+					targets[target] = true
+				}
+			}
+		}
+	}
+
+	rootNode := &OCMNode{Kind: OCMNodeComponent, Component: root}
+	var build func(node *OCMNode, onPath map[*cyclonedx.Component]bool)
+	build = func(node *OCMNode, onPath map[*cyclonedx.Component]bool) {
+		onPath[node.Component] = true
+		defer delete(onPath, node.Component)
+
+		var children []*cyclonedx.Component
+		seen := make(map[*cyclonedx.Component]bool)
+		add := func(comp *cyclonedx.Component) {
+			if comp != nil && !seen[comp] && !onPath[comp] {
+				seen[comp] = true
+				children = append(children, comp)
+			}
+		}
+		if node.Component.Components != nil {
+			for i := range *node.Component.Components {
+				add(&(*node.Component.Components)[i])
+			}
+		}
+		// Packages depend on each other; only component and resource edges form the tree
+		if node.Kind != OCMNodePackage {
+			for _, ref := range dependsOn[node.Ref()] {
+				add(index[ref])
+			}
+		}
+		// Top level components nobody depends on belong to the root
+		if node.Parent == nil && bom.Components != nil {
+			for i := range *bom.Components {
+				if comp := &(*bom.Components)[i]; !targets[comp.BOMRef] {
+					add(comp)
+				}
+			}
+		}
+
+		for _, comp := range children {
+			kind := classifyOCMNode(node.Kind, comp, len(dependsOn[comp.BOMRef]) > 0)
+			child := &OCMNode{Kind: kind, Component: comp, Parent: node}
+			node.Children = append(node.Children, child)
+			build(child, onPath)
+		}
+	}
+	build(rootNode, make(map[*cyclonedx.Component]bool))
+	return rootNode, nil
+}
+
+// classifyOCMNode decides the kind of a node from its parent and its bom-ref or type: OCM
+// bom-refs name their kind, scanned images are containers and component versions are the
+// applications without a purl that the merge creates for every component, which contain or
+// depend on further nodes.
+func classifyOCMNode(parentKind OCMNodeKind, comp *cyclonedx.Component, hasDependencies bool) OCMNodeKind {
+	if parentKind == OCMNodePackage {
+		return OCMNodePackage
+	}
+	if isOCMBOMRef(comp.BOMRef) {
+		rest := strings.TrimPrefix(comp.BOMRef, ocmBOMRefPrefix)
+		if _, resource, found := strings.Cut(rest, "/resource/"); found {
+			if strings.Contains(resource, "/") {
+				return OCMNodePackage
+			}
+			return OCMNodeResource
+		}
+		return OCMNodeComponent
+	}
+	if parentKind == OCMNodeResource {
+		return OCMNodePackage
+	}
+	switch {
+	case comp.Type == cyclonedx.ComponentTypeContainer:
+		return OCMNodeResource
+	case comp.Type == cyclonedx.ComponentTypeApplication && comp.PackageURL == "" && (comp.Components != nil || hasDependencies):
+		return OCMNodeComponent
+	default:
+		return OCMNodePackage
+	}
+}
@@ -0,0 +1,234 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/anchore/stereoscope"
+	stereoscopeevent "github.com/anchore/stereoscope/pkg/event"
+	"github.com/anchore/stereoscope/pkg/event/parsers"
+	"github.com/anchore/stereoscope/pkg/image"
+	"github.com/anchore/syft/syft"
+	"github.com/wagoodman/go-partybus"
+)
+
+// EventType identifies a step of the conversion pipeline.
+type EventType string
+
+const (
+	EventConversionStarted   EventType = "conversion-started"
+	EventConversionFinished  EventType = "conversion-finished"
+	EventComponentDiscovered EventType = "component-discovered"
+	EventComponentFailed     EventType = "component-failed"
+	EventComponentScanned    EventType = "component-scanned"
+	EventComponentMerged     EventType = "component-merged"
+	EventResourceScanStarted EventType = "resource-scan-started"
+	EventResourceScanned     EventType = "resource-scanned"
+	EventResourceFailed      EventType = "resource-failed"
+	EventLayerRead           EventType = "layer-read"
+)
+
+// Event is a single progress notification emitted while converting a component graph.
+// Events are serialised as one JSON object per line by the NDJSON reporter.
+type Event struct {
+	Time      time.Time `json:"time"`
+	Type      EventType `json:"type"`
+	Component string    `json:"component,omitempty"`
+	Resource  string    `json:"resource,omitempty"`
+	Image     string    `json:"image,omitempty"`
+	Layer     string    `json:"layer,omitempty"`
+	Bytes     int64     `json:"bytes,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// ProgressReporter receives pipeline events. Implementations must be safe for concurrent use,
+// since Syft bus events are delivered from a separate goroutine.
+type ProgressReporter interface {
+	Report(e Event)
+}
+
+// NDJSONReporter writes every event as a JSON line to the underlying writer.
+type NDJSONReporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewNDJSONReporter creates a reporter that streams newline-delimited JSON events to w.
+func NewNDJSONReporter(w io.Writer) *NDJSONReporter {
+	return &NDJSONReporter{enc: json.NewEncoder(w)}
+}
+
+// Report implements ProgressReporter.
+func (r *NDJSONReporter) Report(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = r.enc.Encode(e)
+}
+
+// TerminalReporter renders aggregated counters as human-readable status lines.
+type TerminalReporter struct {
+	mu                   sync.Mutex
+	w                    io.Writer
+	discovered           int
+	scanned              int
+	merged               int
+	failed               int
+	resourcesScanned     int
+	bytesRead            int64
+	lastLayerLineWritten time.Time
+}
+
+// NewTerminalReporter creates a reporter that prints a status line to w whenever the counters change.
+func NewTerminalReporter(w io.Writer) *TerminalReporter {
+	return &TerminalReporter{w: w}
+}
+
+// Report implements ProgressReporter.
+func (r *TerminalReporter) Report(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch e.Type {
+	case EventComponentDiscovered:
+		r.discovered++
+	case EventComponentScanned:
+		r.scanned++
+	case EventComponentMerged:
+		r.merged++
+	case EventComponentFailed, EventResourceFailed:
+		r.failed++
+	case EventResourceScanned:
+		r.resourcesScanned++
+	case EventLayerRead:
+		r.bytesRead += e.Bytes
+		// Layer events arrive in bursts; keep the terminal readable.
+		if time.Since(r.lastLayerLineWritten) < time.Second {
+			return
+		}
+		r.lastLayerLineWritten = time.Now()
+	}
+
+	fmt.Fprintf(r.w, "[progress] components: %d discovered, %d scanned, %d merged, %d failed | resources: %d scanned | %s pulled\n",
+		r.discovered, r.scanned, r.merged, r.failed, r.resourcesScanned, humanizeBytes(r.bytesRead))
+}
+
+// MultiReporter fans out events to several reporters.
+type MultiReporter []ProgressReporter
+
+// Report implements ProgressReporter.
+func (m MultiReporter) Report(e Event) {
+	for _, r := range m {
+		if r != nil {
+			r.Report(e)
+		}
+	}
+}
+
+// report stamps and forwards an event to the configured reporter, if any.
+func (c *CLIConverter) report(e Event) {
+	if c == nil || c.Progress == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	c.Progress.Report(e)
+}
+
+// watchSyftBus installs an event bus for Syft and stereoscope and translates the layer read
+// events into EventLayerRead. The returned function detaches the bus again.
+func (c *CLIConverter) watchSyftBus() func() {
+	if c.Progress == nil {
+		return func() {}
+	}
+
+	bus := partybus.NewBus()
+	sub := bus.Subscribe(stereoscopeevent.ReadImage, stereoscopeevent.ReadLayer)
+	syft.SetBus(bus)
+	stereoscope.SetBus(bus)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.translateSyftEvents(sub.Events())
+	}()
+
+	// The bus stays installed after detaching; publishing without subscribers is a no-op.
+	return func() {
+		bus.Close()
+		<-done
+	}
+}
+
+// translateSyftEvents reports an EventLayerRead for every layer read until events is closed.
+// Layer read events only carry the diffID of the layer, its compressed size is taken from the
+// manifest of the image read before.
+func (c *CLIConverter) translateSyftEvents(events <-chan partybus.Event) {
+	sizes := make(map[string]int64) // diffID -> compressed size
+	for ev := range events {
+		switch ev.Type {
+		case stereoscopeevent.ReadImage:
+			metadata, _, err := parsers.ParseReadImage(ev)
+			if err != nil {
+				continue
+			}
+			maps.Copy(sizes, compressedLayerSizes(metadata))
+		case stereoscopeevent.ReadLayer:
+			layer, _, err := parsers.ParseReadLayer(ev)
+			if err != nil {
+				continue
+			}
+			c.report(Event{Type: EventLayerRead, Layer: layer.Digest, Bytes: sizes[layer.Digest]})
+		}
+	}
+}
+
+// compressedLayerSizes maps the diffIDs of an image to the sizes of its layers in the manifest.
+// Images without a manifest, e.g. from a Docker daemon, have no compressed sizes.
+func compressedLayerSizes(metadata *image.Metadata) map[string]int64 {
+	if len(metadata.RawManifest) == 0 {
+		return nil
+	}
+	var manifest struct {
+		Layers []struct {
+			Size int64 `json:"size"`
+		} `json:"layers"`
+	}
+	if err := json.Unmarshal(metadata.RawManifest, &manifest); err != nil {
+		return nil
+	}
+	diffIDs := metadata.Config.RootFS.DiffIDs
+	if len(diffIDs) != len(manifest.Layers) {
+		return nil
+	}
+	sizes := make(map[string]int64, len(diffIDs))
+	for i, diffID := range diffIDs {
+		sizes[diffID.String()] = manifest.Layers[i].Size
+	}
+	return sizes
+}
+
+// humanizeBytes formats a byte count using binary units.
+func humanizeBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
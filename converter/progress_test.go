@@ -0,0 +1,77 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	stereoscopeevent "github.com/anchore/stereoscope/pkg/event"
+	"github.com/anchore/stereoscope/pkg/image"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/wagoodman/go-partybus"
+	"github.com/wagoodman/go-progress"
+)
+
+// recordingReporter keeps all reported events.
+type recordingReporter struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recordingReporter) Report(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func TestTranslateSyftEventsReportsCompressedLayerSizes(t *testing.T) {
+	diffIDs := []v1.Hash{
+		{Algorithm: "sha256", Hex: "1111111111111111111111111111111111111111111111111111111111111111"},
+		{Algorithm: "sha256", Hex: "2222222222222222222222222222222222222222222222222222222222222222"},
+	}
+	manifest, err := json.Marshal(v1.Manifest{
+		SchemaVersion: 2,
+		Layers: []v1.Descriptor{
+			{MediaType: "application/vnd.oci.image.layer.v1.tar+gzip", Size: 3_145_728, Digest: v1.Hash{Algorithm: "sha256", Hex: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"}},
+			{MediaType: "application/vnd.oci.image.layer.v1.tar+gzip", Size: 512, Digest: v1.Hash{Algorithm: "sha256", Hex: "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata := image.Metadata{RawManifest: manifest}
+	metadata.Config.RootFS.DiffIDs = diffIDs
+
+	events := make(chan partybus.Event, 4)
+	events <- partybus.Event{Type: stereoscopeevent.ReadImage, Source: metadata, Value: progress.Progressable(progress.NewManual(4))}
+	for _, diffID := range diffIDs {
+		// stereoscope publishes the layer metadata before the layer is read, its Size is still 0
+		events <- partybus.Event{Type: stereoscopeevent.ReadLayer, Source: image.LayerMetadata{Digest: diffID.String()}, Value: progress.Monitorable(&progress.Manual{})}
+	}
+	// Layers of images without a manifest are reported without a size
+	events <- partybus.Event{Type: stereoscopeevent.ReadLayer, Source: image.LayerMetadata{Digest: "sha256:3333"}, Value: progress.Monitorable(&progress.Manual{})}
+	close(events)
+
+	reporter := &recordingReporter{}
+	c := &CLIConverter{Progress: reporter}
+	c.translateSyftEvents(events)
+
+	want := map[string]int64{diffIDs[0].String(): 3_145_728, diffIDs[1].String(): 512, "sha256:3333": 0}
+	if len(reporter.events) != len(want) {
+		t.Fatalf("reported %d events, want %d: %+v", len(reporter.events), len(want), reporter.events)
+	}
+	for _, e := range reporter.events {
+		if e.Type != EventLayerRead {
+			t.Errorf("event type = %s, want %s", e.Type, EventLayerRead)
+		}
+		if e.Bytes != want[e.Layer] {
+			t.Errorf("bytes of layer %s = %d, want %d", e.Layer, e.Bytes, want[e.Layer])
+		}
+	}
+}
@@ -0,0 +1,273 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
+)
+
+// Annotations of the pushed SBOM artifacts.
+const (
+	annotationTitle            = "org.opencontainers.image.title"
+	annotationComponentVersion = "software.ocm.componentversion"
+	annotationResource         = "software.ocm.resource"
+)
+
+// PublishOptions controls how SBOMs are pushed to OCI registries.
+type PublishOptions struct {
+	// ComponentRepository is the OCI repository holding the component versions, e.g.
+	// "ghcr.io/acme/ocm"; empty uses the OCI registry repository context of the root descriptor.
+	ComponentRepository string
+	// Insecure allows plain HTTP registries.
+	Insecure bool
+	// RemoteOptions configure the registry access; nil authenticates with the default keychain.
+	RemoteOptions []remote.Option
+}
+
+// PublishedSBOM is an SBOM pushed as referrer of an OCI manifest.
+type PublishedSBOM struct {
+	// Subject is the manifest the SBOM refers to.
+	Subject string
+	// Reference is the pushed SBOM artifact, "<repository>@<digest>".
+	Reference    string
+	ArtifactType string
+}
+
+// resourceSBOM is the SBOM of a scanned resource.
+type resourceSBOM struct {
+	Component string
+	Version   string
+	Resource  runtime.Resource
+	ImageRef  string
+	Path      string
+}
+
+// PublishSBOMs pushes the SBOMs of the last conversion as OCI artifacts: the SBOM of each scanned
+// resource as referrer of the image manifest it was scanned from, and the merged SBOM as referrer
+// of the manifest of the root component version. Every format becomes one artifact with the
+// format's media type as artifactType.
+func (c *CLIConverter) PublishSBOMs(sboms map[SBOMFormat][]byte, formats []SBOMFormat, opts PublishOptions) ([]PublishedSBOM, error) {
+	if len(c.componentSBOMs) == 0 {
+		return nil, fmt.Errorf("no SBOMs to publish")
+	}
+	nameOpts := []name.Option{}
+	if opts.Insecure {
+		nameOpts = append(nameOpts, name.Insecure)
+	}
+	remoteOpts := opts.RemoteOptions
+	if remoteOpts == nil {
+		remoteOpts = []remote.Option{remote.WithAuthFromKeychain(authn.DefaultKeychain)}
+	}
+
+	var published []PublishedSBOM
+	for _, rs := range c.resourceSBOMs {
+		subject, err := imageSubject(rs.ImageRef, resourceImageDigest(rs.Resource, rs.ImageRef), nameOpts, remoteOpts)
+		if err != nil {
+			return published, fmt.Errorf("error resolving image of resource %s of %s:%s: %w", rs.Resource.Name, rs.Component, rs.Version, err)
+		}
+		rendered, err := c.renderSBOM(c.componentSBOMOf(rs.Component, rs.Version), rs.Path, formats)
+		if err != nil {
+			return published, fmt.Errorf("error rendering SBOM of resource %s: %w", rs.Resource.Name, err)
+		}
+		for _, format := range formats {
+			annotations := map[string]string{
+				annotationTitle:            sanitizeFilename(rs.Resource.Name) + format.Extension(),
+				annotationComponentVersion: rs.Component + ":" + rs.Version,
+				annotationResource:         rs.Resource.Name + formatIdentity(rs.Resource.ExtraIdentity),
+			}
+			ref, err := PushSBOMReferrer(subject, format, rendered[format], annotations, remoteOpts...)
+			if err != nil {
+				return published, fmt.Errorf("error publishing SBOM of resource %s: %w", rs.Resource.Name, err)
+			}
+			published = append(published, PublishedSBOM{Subject: subject.String(), Reference: ref.String(), ArtifactType: format.MediaType()})
+		}
+	}
+
+	root := c.componentSBOMs[0]
+	repository := opts.ComponentRepository
+	if repository == "" {
+		repository = c.rootOCIRepository
+	}
+	if repository == "" {
+		return published, fmt.Errorf("the root component version %s:%s has no OCI registry repository context, set the component repository to publish its SBOM", root.Name, root.Version)
+	}
+	subject, err := imageSubject(ComponentVersionReference(repository, root.Name, root.Version), "", nameOpts, remoteOpts)
+	if err != nil {
+		return published, fmt.Errorf("error resolving component version %s:%s: %w", root.Name, root.Version, err)
+	}
+	for _, format := range formats {
+		annotations := map[string]string{
+			annotationTitle:            "sbom" + format.Extension(),
+			annotationComponentVersion: root.Name + ":" + root.Version,
+		}
+		ref, err := PushSBOMReferrer(subject, format, sboms[format], annotations, remoteOpts...)
+		if err != nil {
+			return published, fmt.Errorf("error publishing SBOM of %s:%s: %w", root.Name, root.Version, err)
+		}
+		published = append(published, PublishedSBOM{Subject: subject.String(), Reference: ref.String(), ArtifactType: format.MediaType()})
+	}
+	return published, nil
+}
+
+// ComponentVersionReference returns the reference of the manifest of a component version in an
+// OCI repository: "<repository>/component-descriptors/<component>:<version>", with "+" in the
+// version replaced as OCM does for tags.
+func ComponentVersionReference(repository, component, version string) string {
+	return fmt.Sprintf("%s/component-descriptors/%s:%s", strings.TrimSuffix(repository, "/"), component, strings.ReplaceAll(version, "+", ".build-"))
+}
+
+// ociRepository returns the OCI repository of the most recent OCI registry repository context of
+// a descriptor, e.g. "ghcr.io/acme/ocm", or "" if it has none.
+func ociRepository(descriptor *runtime.Descriptor) string {
+	contexts := descriptor.Component.RepositoryContexts
+	if len(contexts) == 0 {
+		return ""
+	}
+	spec, err := accessSpec(&contexts[len(contexts)-1])
+	if err != nil || accessType(spec) != "ociregistry" {
+		return ""
+	}
+	baseURL, _ := spec["baseUrl"].(string)
+	if baseURL == "" {
+		return ""
+	}
+	repository := strings.TrimSuffix(baseURL, "/")
+	for _, scheme := range []string{"https://", "http://", "oci://"} {
+		repository = strings.TrimPrefix(repository, scheme)
+	}
+	if subPath, _ := spec["subPath"].(string); subPath != "" {
+		repository += "/" + strings.Trim(subPath, "/")
+	}
+	return repository
+}
+
+// imageSubject resolves an image reference to the digest of its manifest; a known digest is used
+// as is.
+func imageSubject(imageRef, digest string, nameOpts []name.Option, remoteOpts []remote.Option) (name.Digest, error) {
+	ref, err := name.ParseReference(imageRef, nameOpts...)
+	if err != nil {
+		return name.Digest{}, err
+	}
+	if digest == "" {
+		if d, pinned := ref.(name.Digest); pinned {
+			return d, nil
+		}
+		desc, err := remote.Head(ref, remoteOpts...)
+		if err != nil {
+			return name.Digest{}, err
+		}
+		digest = desc.Digest.String()
+	}
+	return ref.Context().Digest(digest), nil
+}
+
+// artifactManifest is an OCI image manifest describing an artifact.
+type artifactManifest struct {
+	v1.Manifest
+	ArtifactType string `json:"artifactType,omitempty"`
+}
+
+// rawManifest is a serialized manifest ready to be pushed.
+type rawManifest struct {
+	raw       []byte
+	mediaType types.MediaType
+}
+
+func (m rawManifest) RawManifest() ([]byte, error)        { return m.raw, nil }
+func (m rawManifest) MediaType() (types.MediaType, error) { return m.mediaType, nil }
+
+// PushSBOMReferrer pushes an SBOM as OCI artifact into the repository of subject, with the
+// format's media type as artifactType and subject as the manifest it refers to, so it is listed
+// by the referrers API of the subject (or its fallback tag on registries without one). It
+// returns the digest of the pushed manifest.
+func PushSBOMReferrer(subject name.Digest, format SBOMFormat, content []byte, annotations map[string]string, options ...remote.Option) (name.Digest, error) {
+	subjectDesc, err := remote.Head(subject, options...)
+	if err != nil {
+		return name.Digest{}, fmt.Errorf("failed to get subject %s: %w", subject, err)
+	}
+	repo := subject.Context()
+
+	// The config carries the SBOM media type instead of the empty config type: registries without
+	// the referrers API (and some with it) derive the artifactType of referrers from the config
+	config := static.NewLayer([]byte("{}"), types.MediaType(format.MediaType()))
+	layer := static.NewLayer(content, types.MediaType(format.MediaType()))
+	configDesc, err := layerDescriptor(config)
+	if err != nil {
+		return name.Digest{}, err
+	}
+	layerDesc, err := layerDescriptor(layer)
+	if err != nil {
+		return name.Digest{}, err
+	}
+	if title := annotations[annotationTitle]; title != "" {
+		layerDesc.Annotations = map[string]string{annotationTitle: title}
+	}
+	for _, blob := range []v1.Layer{config, layer} {
+		if err := remote.WriteLayer(repo, blob, options...); err != nil {
+			return name.Digest{}, fmt.Errorf("failed to upload blob to %s: %w", repo, err)
+		}
+	}
+
+	manifest := artifactManifest{
+		Manifest: v1.Manifest{
+			SchemaVersion: 2,
+			MediaType:     types.OCIManifestSchema1,
+			Config:        configDesc,
+			Layers:        []v1.Descriptor{layerDesc},
+			Annotations:   annotations,
+			Subject: &v1.Descriptor{
+				MediaType: subjectDesc.MediaType,
+				Size:      subjectDesc.Size,
+				Digest:    subjectDesc.Digest,
+			},
+		},
+		ArtifactType: format.MediaType(),
+	}
+	raw, err := json.Marshal(manifest)
+	if err != nil {
+		return name.Digest{}, err
+	}
+	digest, _, err := v1.SHA256(bytes.NewReader(raw))
+	if err != nil {
+		return name.Digest{}, err
+	}
+	ref := repo.Digest(digest.String())
+	if err := remote.Put(ref, rawManifest{raw: raw, mediaType: types.OCIManifestSchema1}, options...); err != nil {
+		return name.Digest{}, fmt.Errorf("failed to push SBOM manifest to %s: %w", repo, err)
+	}
+	log.Printf("Published %s SBOM %s referring to %s", format, ref, subject)
+	return ref, nil
+}
+
+// layerDescriptor describes a blob of a manifest.
+func layerDescriptor(layer v1.Layer) (v1.Descriptor, error) {
+	digest, err := layer.Digest()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	size, err := layer.Size()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	mediaType, err := layer.MediaType()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	return v1.Descriptor{MediaType: mediaType, Size: size, Digest: digest}, nil
+}
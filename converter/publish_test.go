@@ -0,0 +1,152 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
+)
+
+// testRegistry starts an in-memory OCI registry and returns its host.
+func testRegistry(t *testing.T, referrersAPI bool) (string, []remote.Option) {
+	t.Helper()
+	srv := httptest.NewServer(registry.New(registry.WithReferrersSupport(referrersAPI), registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://"), []remote.Option{remote.WithTransport(srv.Client().Transport)}
+}
+
+// pushRandomImage pushes a random image and returns the digest reference of its manifest.
+func pushRandomImage(t *testing.T, ref string, opts []remote.Option) name.Digest {
+	t.Helper()
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := name.ParseReference(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(tag, img, opts...); err != nil {
+		t.Fatalf("pushing %s: %v", ref, err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tag.Context().Digest(digest.String())
+}
+
+// referrersByType lists the referrers of subject by artifactType.
+func referrersByType(t *testing.T, subject name.Digest, opts []remote.Option) map[string]v1.Descriptor {
+	t.Helper()
+	index, err := remote.Referrers(subject, opts...)
+	if err != nil {
+		t.Fatalf("listing referrers of %s: %v", subject, err)
+	}
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	byType := make(map[string]v1.Descriptor)
+	for _, desc := range manifest.Manifests {
+		byType[desc.ArtifactType] = desc
+	}
+	return byType
+}
+
+func TestSBOMFormatMediaTypesAreDistinct(t *testing.T) {
+	seen := make(map[string]SBOMFormat)
+	for _, format := range []SBOMFormat{FormatCycloneDXJSON, FormatCycloneDXXML, FormatCycloneDXYAML, FormatSPDXJSON, FormatSPDXYAML, FormatSPDXTagValue, FormatSPDX3JSON} {
+		mediaType := format.MediaType()
+		if other, dup := seen[mediaType]; dup {
+			t.Errorf("%s and %s share the media type %s", other, format, mediaType)
+		}
+		seen[mediaType] = format
+	}
+}
+
+func TestPublishSBOMsSetsArtifactType(t *testing.T) {
+	formats := []SBOMFormat{FormatCycloneDXJSON, FormatSPDXJSON, FormatSPDX3JSON}
+	for _, mode := range []struct {
+		name         string
+		referrersAPI bool
+	}{{"referrers API", true}, {"fallback tag", false}} {
+		t.Run(mode.name, func(t *testing.T) {
+			host, opts := testRegistry(t, mode.referrersAPI)
+			image := pushRandomImage(t, host+"/acme/app:1.0", opts)
+			componentVersion := pushRandomImage(t, ComponentVersionReference(host+"/ocm", "acme.org/app", "1.0.0"), opts)
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "image.cdx.json")
+			bom := `{"bomFormat":"CycloneDX","specVersion":"1.6","version":1,"metadata":{"component":{"type":"container","name":"image","bom-ref":"image"}}}`
+			if err := os.WriteFile(path, []byte(bom), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			resource := runtime.Resource{}
+			resource.Name = "image"
+			c := &CLIConverter{TempDir: dir, mergeTool: "native"}
+			c.componentSBOMs = []componentSBOM{{Name: "acme.org/app", Version: "1.0.0", Path: path}}
+			c.resourceSBOMs = []resourceSBOM{{Component: "acme.org/app", Version: "1.0.0", Resource: resource, ImageRef: host + "/acme/app:1.0", Path: path}}
+
+			sboms := make(map[SBOMFormat][]byte)
+			for _, format := range formats {
+				sboms[format] = []byte("{}")
+			}
+			published, err := c.PublishSBOMs(sboms, formats, PublishOptions{ComponentRepository: host + "/ocm", RemoteOptions: opts})
+			if err != nil {
+				t.Fatalf("PublishSBOMs: %v", err)
+			}
+			if len(published) != 2*len(formats) {
+				t.Errorf("published %d SBOMs, want %d", len(published), 2*len(formats))
+			}
+
+			for subject, title := range map[name.Digest]string{image: "image", componentVersion: "sbom"} {
+				referrers := referrersByType(t, subject, opts)
+				if len(referrers) != len(formats) {
+					t.Errorf("referrers of %s = %v, want one per format", subject, referrers)
+				}
+				for _, format := range formats {
+					desc, ok := referrers[format.MediaType()]
+					if !ok {
+						t.Errorf("no referrer of %s with artifactType %s", subject, format.MediaType())
+						continue
+					}
+					manifest, err := remote.Get(subject.Context().Digest(desc.Digest.String()), opts...)
+					if err != nil {
+						t.Fatalf("fetching %s referrer: %v", format, err)
+					}
+					var artifact artifactManifest
+					if err := json.Unmarshal(manifest.Manifest, &artifact); err != nil {
+						t.Fatal(err)
+					}
+					if artifact.ArtifactType != format.MediaType() || len(artifact.Layers) != 1 || string(artifact.Layers[0].MediaType) != format.MediaType() {
+						t.Errorf("%s referrer manifest = %s", format, manifest.Manifest)
+					}
+					if artifact.Subject == nil || artifact.Subject.Digest.String() != subject.DigestStr() {
+						t.Errorf("%s referrer subject = %+v, want %s", format, artifact.Subject, subject.DigestStr())
+					}
+					if got := artifact.Annotations[annotationTitle]; got != title+format.Extension() {
+						t.Errorf("title of %s referrer = %q, want %q", format, got, title+format.Extension())
+					}
+				}
+			}
+		})
+	}
+}
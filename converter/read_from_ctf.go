@@ -15,7 +15,13 @@ import (
 )
 
 func createRepository(ctfFolderPath string) (oci.ComponentVersionRepository, error) {
-	archive, err := ctf.OpenCTFFromOSPath(ctfFolderPath, ctf.O_RDONLY)
+	return openRepository(ctfFolderPath, ctf.O_RDONLY)
+}
+
+// openRepository opens the component versions of a CTF folder, read-only with ctf.O_RDONLY or
+// for writing with ctf.O_RDWR.
+func openRepository(ctfFolderPath string, flag int) (oci.ComponentVersionRepository, error) {
+	archive, err := ctf.OpenCTFFromOSPath(ctfFolderPath, flag)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open CTF archive: %w", err)
 	}
@@ -0,0 +1,413 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+// ReportFormat selects how a report is rendered.
+type ReportFormat string
+
+const (
+	ReportFormatHTML     ReportFormat = "html"
+	ReportFormatMarkdown ReportFormat = "markdown"
+)
+
+// Extension returns the file extension conventionally used for the report format.
+func (f ReportFormat) Extension() string {
+	switch f {
+	case ReportFormatHTML:
+		return ".html"
+	case ReportFormatMarkdown:
+		return ".md"
+	default:
+		return ""
+	}
+}
+
+// unknownLicense is shown for packages without license information.
+const unknownLicense = "unknown"
+
+// Report summarizes a merged BOM for human readers.
+type Report struct {
+	Title           string
+	Timestamp       string
+	Tools           []string
+	Root            *ReportComponent
+	Totals          ReportTotals
+	Licenses        []LicenseCount
+	Vulnerabilities []ReportVulnerability
+}
+
+// ReportTotals counts the nodes of the whole BOM; packages are counted once per bom-ref.
+type ReportTotals struct {
+	Components      int
+	Images          int
+	Packages        int
+	Vulnerabilities int
+}
+
+// ReportComponent describes one OCM component version of the report.
+type ReportComponent struct {
+	Name            string
+	Version         string
+	Depth           int
+	Images          []ReportImage
+	Packages        int
+	Licenses        []LicenseCount
+	Vulnerabilities int
+	Errors          []string
+	Children        []*ReportComponent
+}
+
+// ReportImage describes a scanned resource of a component version.
+type ReportImage struct {
+	Name            string
+	Version         string
+	Packages        int
+	Vulnerabilities int
+}
+
+// LicenseCount is the number of packages under a license expression.
+type LicenseCount struct {
+	License string
+	Count   int
+}
+
+// ReportVulnerability is a vulnerability with the packages and component versions it affects.
+type ReportVulnerability struct {
+	ID         string
+	Severity   string
+	Score      string
+	Packages   []string
+	Components []string
+}
+
+// BuildReport summarizes a merged BOM: the component tree with images and package counts,
+// the license breakdown and the vulnerabilities.
+func BuildReport(bom *cyclonedx.BOM) (*Report, error) {
+	tree, err := BuildOCMTree(bom)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Title: tree.Label(), Timestamp: bom.Metadata.Timestamp}
+	if bom.Metadata.Tools != nil && bom.Metadata.Tools.Components != nil {
+		for _, tool := range *bom.Metadata.Tools.Components {
+			name := tool.Name
+			if tool.Version != "" {
+				name += " " + tool.Version
+			}
+			report.Tools = append(report.Tools, name)
+		}
+	}
+
+	// Vulnerabilities by affected bom-ref
+	vulnsByRef := make(map[string][]int)
+	if bom.Vulnerabilities != nil {
+		for i, vulnerability := range *bom.Vulnerabilities {
+			if vulnerability.Affects == nil {
+				continue
+			}
+			for _, affect := range *vulnerability.Affects {
+				vulnsByRef[affect.Ref] = append(vulnsByRef[affect.Ref], i)
+			}
+		}
+	}
+	affectedPackages := make(map[int]map[string]bool)
+	affectedComponents := make(map[int]map[string]bool)
+
+	seenPackages := make(map[string]bool)
+	licenses := make(map[string]int)
+	var build func(node *OCMNode) *ReportComponent
+	build = func(node *OCMNode) *ReportComponent {
+		report.Totals.Components++
+		rc := &ReportComponent{
+			Name:    node.Component.Name,
+			Version: node.Component.Version,
+			Depth:   node.Depth(),
+			Errors:  failureProperties(node.Component),
+		}
+		componentLicenses := make(map[string]int)
+		componentVulns := make(map[int]bool)
+
+		countPackages := func(packages []*OCMNode) (int, int) {
+			imageVulns := make(map[int]bool)
+			for _, pkg := range packages {
+				license := spdxLicenseExpression(pkg.Component.Licenses)
+				if license == spdxNoAssertion {
+					license = unknownLicense
+				}
+				componentLicenses[license]++
+				if !seenPackages[pkg.Ref()] || pkg.Ref() == "" {
+					seenPackages[pkg.Ref()] = true
+					report.Totals.Packages++
+					licenses[license]++
+				}
+				for _, i := range vulnsByRef[pkg.Ref()] {
+					imageVulns[i] = true
+					componentVulns[i] = true
+					addToSet(affectedPackages, i, pkg.Label())
+					addToSet(affectedComponents, i, node.Label())
+				}
+			}
+			return len(packages), len(imageVulns)
+		}
+
+		for _, child := range node.Children {
+			switch child.Kind {
+			case OCMNodeResource:
+				report.Totals.Images++
+				packages, vulns := countPackages(child.Packages())
+				rc.Images = append(rc.Images, ReportImage{Name: child.Component.Name, Version: child.Component.Version, Packages: packages, Vulnerabilities: vulns})
+				rc.Packages += packages
+			case OCMNodePackage:
+				packages, _ := countPackages(append([]*OCMNode{child}, child.Packages()...))
+				rc.Packages += packages
+			case OCMNodeComponent:
+				rc.Children = append(rc.Children, build(child))
+			}
+		}
+		rc.Licenses = sortedLicenseCounts(componentLicenses)
+		rc.Vulnerabilities = len(componentVulns)
+		return rc
+	}
+	report.Root = build(tree)
+	report.Licenses = sortedLicenseCounts(licenses)
+
+	if bom.Vulnerabilities != nil {
+		for i, vulnerability := range *bom.Vulnerabilities {
+			severity, score := highestRating(vulnerability)
+			report.Vulnerabilities = append(report.Vulnerabilities, ReportVulnerability{
+				ID:         vulnerability.ID,
+				Severity:   severity,
+				Score:      score,
+				Packages:   sortedKeys(affectedPackages[i]),
+				Components: sortedKeys(affectedComponents[i]),
+			})
+		}
+		sort.SliceStable(report.Vulnerabilities, func(i, j int) bool {
+			a, b := report.Vulnerabilities[i], report.Vulnerabilities[j]
+			if severityRank(a.Severity) != severityRank(b.Severity) {
+				return severityRank(a.Severity) > severityRank(b.Severity)
+			}
+			return a.ID < b.ID
+		})
+	}
+	report.Totals.Vulnerabilities = len(report.Vulnerabilities)
+	return report, nil
+}
+
+// Components returns the component versions of the report in depth first order.
+func (r *Report) Components() []*ReportComponent {
+	var result []*ReportComponent
+	var walk func(rc *ReportComponent)
+	walk = func(rc *ReportComponent) {
+		result = append(result, rc)
+		for _, child := range rc.Children {
+			walk(child)
+		}
+	}
+	if r.Root != nil {
+		walk(r.Root)
+	}
+	return result
+}
+
+// RenderReport writes the report in the given format.
+func RenderReport(report *Report, w io.Writer, format ReportFormat) error {
+	switch format {
+	case ReportFormatHTML:
+		return renderHTMLReport(report, w)
+	case ReportFormatMarkdown:
+		return renderMarkdownReport(report, w)
+	default:
+		return fmt.Errorf("unsupported report format: %s", format)
+	}
+}
+
+//go:embed report.html.tmpl
+var reportHTMLTemplate string
+
+// renderHTMLReport writes a self-contained HTML page with collapsible component sections.
+func renderHTMLReport(report *Report, w io.Writer) error {
+	tmpl, err := template.New("report").Parse(reportHTMLTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse HTML report template: %w", err)
+	}
+	if err := tmpl.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	return nil
+}
+
+// renderMarkdownReport writes a Markdown summary; component sections collapse with <details>.
+func renderMarkdownReport(report *Report, w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# SBOM report: %s\n\n", markdownEscape(report.Title))
+	if report.Timestamp != "" {
+		fmt.Fprintf(&b, "Generated: %s  \n", report.Timestamp)
+	}
+	if len(report.Tools) > 0 {
+		fmt.Fprintf(&b, "Tools: %s\n", markdownEscape(strings.Join(report.Tools, ", ")))
+	}
+
+	b.WriteString("\n## Summary\n\n| Component versions | Images | Packages | Vulnerabilities |\n|---:|---:|---:|---:|\n")
+	fmt.Fprintf(&b, "| %d | %d | %d | %d |\n", report.Totals.Components, report.Totals.Images, report.Totals.Packages, report.Totals.Vulnerabilities)
+
+	b.WriteString("\n## Component tree\n\n")
+	for _, rc := range report.Components() {
+		fmt.Fprintf(&b, "%s- **%s** %s (%d images, %d packages", strings.Repeat("  ", rc.Depth), markdownEscape(rc.Name), markdownEscape(rc.Version), len(rc.Images), rc.Packages)
+		if rc.Vulnerabilities > 0 {
+			fmt.Fprintf(&b, ", %d vulnerabilities", rc.Vulnerabilities)
+		}
+		b.WriteString(")\n")
+	}
+
+	b.WriteString("\n## Licenses\n\n")
+	writeMarkdownLicenses(&b, report.Licenses)
+
+	if len(report.Vulnerabilities) > 0 {
+		b.WriteString("\n## Vulnerabilities\n\n| ID | Severity | Score | Packages | Components |\n|---|---|---:|---|---|\n")
+		for _, v := range report.Vulnerabilities {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", markdownEscape(v.ID), v.Severity, v.Score,
+				markdownEscape(strings.Join(v.Packages, ", ")), markdownEscape(strings.Join(v.Components, ", ")))
+		}
+	}
+
+	b.WriteString("\n## Components\n")
+	for _, rc := range report.Components() {
+		fmt.Fprintf(&b, "\n<details>\n<summary><b>%s</b> %s</summary>\n\n", template.HTMLEscapeString(rc.Name), template.HTMLEscapeString(rc.Version))
+		for _, e := range rc.Errors {
+			fmt.Fprintf(&b, "> **Error:** %s\n\n", markdownEscape(e))
+		}
+		if len(rc.Images) > 0 {
+			b.WriteString("| Image | Version | Packages | Vulnerabilities |\n|---|---|---:|---:|\n")
+			for _, image := range rc.Images {
+				fmt.Fprintf(&b, "| %s | %s | %d | %d |\n", markdownEscape(image.Name), markdownEscape(image.Version), image.Packages, image.Vulnerabilities)
+			}
+			b.WriteString("\n")
+		} else {
+			b.WriteString("No images.\n\n")
+		}
+		if len(rc.Licenses) > 0 {
+			writeMarkdownLicenses(&b, rc.Licenses)
+		}
+		b.WriteString("\n</details>\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMarkdownLicenses writes a license breakdown table.
+func writeMarkdownLicenses(b *strings.Builder, licenses []LicenseCount) {
+	if len(licenses) == 0 {
+		b.WriteString("No packages.\n")
+		return
+	}
+	b.WriteString("| License | Packages |\n|---|---:|\n")
+	for _, license := range licenses {
+		fmt.Fprintf(b, "| %s | %d |\n", markdownEscape(license.License), license.Count)
+	}
+}
+
+// markdownEscape escapes the characters that break Markdown tables and emphasis.
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "*", `\*`, "_", `\_`, "<", "&lt;", ">", "&gt;", "\n", " ").Replace(s)
+}
+
+// failureProperties returns the recorded scan and lookup errors of a component.
+func failureProperties(comp *cyclonedx.Component) []string {
+	if comp.Properties == nil {
+		return nil
+	}
+	var errs []string
+	for _, prop := range *comp.Properties {
+		if strings.HasPrefix(prop.Name, errorPropertyPrefix) {
+			errs = append(errs, prop.Value)
+		}
+	}
+	return errs
+}
+
+// highestRating returns the most severe rating of a vulnerability.
+func highestRating(vulnerability cyclonedx.Vulnerability) (string, string) {
+	severity, score := string(cyclonedx.SeverityUnknown), ""
+	if vulnerability.Ratings == nil {
+		return severity, score
+	}
+	for _, rating := range *vulnerability.Ratings {
+		if severityRank(string(rating.Severity)) > severityRank(severity) {
+			severity = string(rating.Severity)
+			score = ""
+			if rating.Score != nil {
+				score = fmt.Sprintf("%.1f", *rating.Score)
+			}
+		}
+	}
+	return severity, score
+}
+
+// severityRank orders severities from unknown to critical.
+func severityRank(severity string) int {
+	switch cyclonedx.Severity(strings.ToLower(severity)) {
+	case cyclonedx.SeverityCritical:
+		return 5
+	case cyclonedx.SeverityHigh:
+		return 4
+	case cyclonedx.SeverityMedium:
+		return 3
+	case cyclonedx.SeverityLow:
+		return 2
+	case cyclonedx.SeverityInfo, cyclonedx.SeverityNone:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// sortedLicenseCounts orders licenses by package count, then name.
+func sortedLicenseCounts(counts map[string]int) []LicenseCount {
+	result := make([]LicenseCount, 0, len(counts))
+	for license, count := range counts {
+		result = append(result, LicenseCount{License: license, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].License < result[j].License
+	})
+	return result
+}
+
+// addToSet adds a value to the set stored under key.
+func addToSet(sets map[int]map[string]bool, key int, value string) {
+	if sets[key] == nil {
+		sets[key] = make(map[string]bool)
+	}
+	sets[key][value] = true
+}
+
+// sortedKeys returns the keys of a set in order.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,112 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+// reportBOM returns a component version with one image and a nested component version with
+// another image; both images contain zlib.
+func reportBOM() *cyclonedx.BOM {
+	license := func(id string) *cyclonedx.Licenses {
+		return &cyclonedx.Licenses{{License: &cyclonedx.License{ID: id}}}
+	}
+	zlib := cyclonedx.Component{Type: cyclonedx.ComponentTypeLibrary, Name: "zlib", Version: "1.3", BOMRef: "zlib", Licenses: license("Zlib")}
+	score := 9.8
+	bom := cyclonedx.NewBOM()
+	bom.Metadata = &cyclonedx.Metadata{Component: &cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/app", Version: "1.0.0", BOMRef: "app"}}
+	bom.Components = &[]cyclonedx.Component{
+		{Type: cyclonedx.ComponentTypeContainer, Name: "web|front", Version: "1.0.0", BOMRef: "frontend", Components: &[]cyclonedx.Component{
+			zlib,
+			{Type: cyclonedx.ComponentTypeLibrary, Name: "openssl", Version: "3.3", BOMRef: "openssl", Licenses: license("Apache-2.0")},
+		}},
+		{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/lib_core", Version: "2.0.0", BOMRef: "lib", Components: &[]cyclonedx.Component{
+			{Type: cyclonedx.ComponentTypeContainer, Name: "backend", Version: "2.0.0", BOMRef: "backend", Components: &[]cyclonedx.Component{
+				zlib,
+				{Type: cyclonedx.ComponentTypeLibrary, Name: "musl", Version: "1.2.5", BOMRef: "musl"},
+			}},
+		}},
+	}
+	bom.Dependencies = &[]cyclonedx.Dependency{
+		{Ref: "app", Dependencies: &[]string{"frontend", "lib"}},
+		{Ref: "lib", Dependencies: &[]string{"backend"}},
+	}
+	bom.Vulnerabilities = &[]cyclonedx.Vulnerability{
+		{ID: "CVE-2024-0002", Ratings: &[]cyclonedx.VulnerabilityRating{{Severity: cyclonedx.SeverityMedium}}, Affects: &[]cyclonedx.Affects{{Ref: "zlib"}}},
+		{ID: "CVE-2024-0001", Ratings: &[]cyclonedx.VulnerabilityRating{{Severity: cyclonedx.SeverityLow}, {Severity: cyclonedx.SeverityCritical, Score: &score}}, Affects: &[]cyclonedx.Affects{{Ref: "openssl"}}},
+	}
+	return bom
+}
+
+func TestBuildReport(t *testing.T) {
+	report, err := BuildReport(reportBOM())
+	if err != nil {
+		t.Fatalf("BuildReport: %v", err)
+	}
+
+	// zlib is counted once in the totals, but in both component versions
+	if want := (ReportTotals{Components: 2, Images: 2, Packages: 3, Vulnerabilities: 2}); report.Totals != want {
+		t.Errorf("totals = %+v, want %+v", report.Totals, want)
+	}
+	components := report.Components()
+	if len(components) != 2 {
+		t.Fatalf("components = %+v, want app and lib", components)
+	}
+	app, lib := components[0], components[1]
+	if want := []ReportImage{{Name: "web|front", Version: "1.0.0", Packages: 2, Vulnerabilities: 2}}; app.Packages != 2 || app.Vulnerabilities != 2 || !reflect.DeepEqual(app.Images, want) {
+		t.Errorf("app = %d packages, %d vulnerabilities, images %+v", app.Packages, app.Vulnerabilities, app.Images)
+	}
+	if want := []ReportImage{{Name: "backend", Version: "2.0.0", Packages: 2, Vulnerabilities: 1}}; lib.Depth != 1 || lib.Packages != 2 || lib.Vulnerabilities != 1 || !reflect.DeepEqual(lib.Images, want) {
+		t.Errorf("lib = depth %d, %d packages, %d vulnerabilities, images %+v", lib.Depth, lib.Packages, lib.Vulnerabilities, lib.Images)
+	}
+
+	if want := []LicenseCount{{"Apache-2.0", 1}, {"Zlib", 1}, {unknownLicense, 1}}; !reflect.DeepEqual(report.Licenses, want) {
+		t.Errorf("licenses = %+v, want %+v", report.Licenses, want)
+	}
+	if want := []LicenseCount{{"Zlib", 1}, {unknownLicense, 1}}; !reflect.DeepEqual(lib.Licenses, want) {
+		t.Errorf("licenses of lib = %+v, want %+v", lib.Licenses, want)
+	}
+
+	// Most severe first, attributed to the component versions containing the package
+	want := []ReportVulnerability{
+		{ID: "CVE-2024-0001", Severity: "critical", Score: "9.8", Packages: []string{"openssl@3.3"}, Components: []string{"acme.org/app@1.0.0"}},
+		{ID: "CVE-2024-0002", Severity: "medium", Packages: []string{"zlib@1.3"}, Components: []string{"acme.org/app@1.0.0", "acme.org/lib_core@2.0.0"}},
+	}
+	if !reflect.DeepEqual(report.Vulnerabilities, want) {
+		t.Errorf("vulnerabilities =\n%+v\nwant\n%+v", report.Vulnerabilities, want)
+	}
+}
+
+func TestRenderMarkdownReportEscapes(t *testing.T) {
+	report, err := BuildReport(reportBOM())
+	if err != nil {
+		t.Fatalf("BuildReport: %v", err)
+	}
+	var b strings.Builder
+	if err := RenderReport(report, &b, ReportFormatMarkdown); err != nil {
+		t.Fatalf("RenderReport: %v", err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"| 2 | 2 | 3 | 2 |",
+		"  - **acme.org/lib\\_core** 2.0.0 (1 images, 2 packages, 1 vulnerabilities)",
+		"| web\\|front | 1.0.0 | 2 | 2 |",
+		"| CVE-2024-0002 | medium |  | zlib@1.3 | acme.org/app@1.0.0, acme.org/lib\\_core@2.0.0 |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "web|front") {
+		t.Errorf("report contains an unescaped table cell:\n%s", out)
+	}
+}
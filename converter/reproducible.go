@@ -0,0 +1,200 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/google/uuid"
+	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
+)
+
+// SourceDateEpochEnv is the environment variable (see reproducible-builds.org) that overrides
+// the timestamp of reproducible SBOMs.
+const SourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+// serialNamespace is the UUIDv5 namespace for serial numbers of reproducible SBOMs.
+var serialNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/olisonsturm/ocm-sbom"))
+
+// ReproducibleTimestamp returns the timestamp for a reproducible SBOM: SOURCE_DATE_EPOCH if set,
+// otherwise the creation time of the root component descriptor, otherwise the Unix epoch.
+func ReproducibleTimestamp(creationTime string) (time.Time, error) {
+	if epoch := os.Getenv(SourceDateEpochEnv); epoch != "" {
+		seconds, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s %q: %w", SourceDateEpochEnv, epoch, err)
+		}
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	if creationTime != "" {
+		ts, err := time.Parse(time.RFC3339, creationTime)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid descriptor creationTime %q: %w", creationTime, err)
+		}
+		return ts.UTC(), nil
+	}
+	return time.Unix(0, 0).UTC(), nil
+}
+
+// descriptorCreationTime reads the creationTime of a component descriptor, if set.
+func descriptorCreationTime(descriptor *runtime.Descriptor) string {
+	raw, err := json.Marshal(descriptor.Component)
+	if err != nil {
+		return ""
+	}
+	var meta struct {
+		CreationTime string `json:"creationTime"`
+	}
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return ""
+	}
+	return meta.CreationTime
+}
+
+// MakeReproducible rewrites the run dependent parts of a BOM so identical inputs give identical
+// bytes: the timestamp is fixed, components, dependencies and properties are sorted canonically
+// and the serial number becomes a UUIDv5 of the root component identity and the BOM content.
+func MakeReproducible(bom *cyclonedx.BOM, timestamp time.Time) error {
+	if bom == nil {
+		return nil
+	}
+	if bom.Metadata == nil {
+		bom.Metadata = &cyclonedx.Metadata{}
+	}
+	bom.Metadata.Timestamp = timestamp.UTC().Format(time.RFC3339)
+	bom.SerialNumber = ""
+	canonicalizeBOM(bom)
+
+	content, err := json.Marshal(bom)
+	if err != nil {
+		return fmt.Errorf("failed to encode BOM for serial number: %w", err)
+	}
+	identity := ""
+	if bom.Metadata.Component != nil {
+		identity = componentBOMRefNamespace(bom.Metadata.Component)
+	}
+	serial := uuid.NewSHA1(serialNamespace, append([]byte(identity+"\n"), content...))
+	bom.SerialNumber = fmt.Sprintf("urn:uuid:%s", serial.String())
+	return nil
+}
+
+// canonicalizeBOM sorts all unordered lists of a BOM.
+func canonicalizeBOM(bom *cyclonedx.BOM) {
+	if bom.Metadata != nil {
+		sortProperties(bom.Metadata.Properties)
+		if bom.Metadata.Component != nil {
+			canonicalizeComponent(bom.Metadata.Component)
+		}
+	}
+	if bom.Components != nil {
+		sortComponents(*bom.Components)
+	}
+	if bom.Services != nil {
+		sort.SliceStable(*bom.Services, func(i, j int) bool {
+			a, b := (*bom.Services)[i], (*bom.Services)[j]
+			return a.BOMRef+"\x00"+a.Name < b.BOMRef+"\x00"+b.Name
+		})
+	}
+	sortProperties(bom.Properties)
+
+	if bom.Dependencies != nil {
+		for _, dep := range *bom.Dependencies {
+			if dep.Dependencies != nil {
+				sort.Strings(*dep.Dependencies)
+			}
+		}
+		sort.SliceStable(*bom.Dependencies, func(i, j int) bool {
+			return (*bom.Dependencies)[i].Ref < (*bom.Dependencies)[j].Ref
+		})
+	}
+
+	if bom.Compositions != nil {
+		for _, composition := range *bom.Compositions {
+			for _, refs := range []*[]cyclonedx.BOMReference{composition.Assemblies, composition.Dependencies} {
+				if refs != nil {
+					sort.SliceStable(*refs, func(i, j int) bool { return (*refs)[i] < (*refs)[j] })
+				}
+			}
+		}
+		sort.SliceStable(*bom.Compositions, func(i, j int) bool {
+			return compositionKey((*bom.Compositions)[i]) < compositionKey((*bom.Compositions)[j])
+		})
+	}
+
+	if bom.Vulnerabilities != nil {
+		for _, vulnerability := range *bom.Vulnerabilities {
+			if vulnerability.Affects != nil {
+				sort.SliceStable(*vulnerability.Affects, func(i, j int) bool {
+					return (*vulnerability.Affects)[i].Ref < (*vulnerability.Affects)[j].Ref
+				})
+			}
+			sortProperties(vulnerability.Properties)
+		}
+		sort.SliceStable(*bom.Vulnerabilities, func(i, j int) bool {
+			a, b := (*bom.Vulnerabilities)[i], (*bom.Vulnerabilities)[j]
+			return a.ID+"\x00"+a.BOMRef < b.ID+"\x00"+b.BOMRef
+		})
+	}
+}
+
+// canonicalizeComponent sorts the properties and nested components of a component.
+func canonicalizeComponent(comp *cyclonedx.Component) {
+	sortProperties(comp.Properties)
+	if comp.Components != nil {
+		sortComponents(*comp.Components)
+	}
+}
+
+// sortComponents sorts components by bom-ref and identity, recursively.
+func sortComponents(components []cyclonedx.Component) {
+	for i := range components {
+		canonicalizeComponent(&components[i])
+	}
+	sort.SliceStable(components, func(i, j int) bool {
+		return componentSortKey(components[i]) < componentSortKey(components[j])
+	})
+}
+
+// componentSortKey orders components by bom-ref, falling back to their identity.
+func componentSortKey(comp cyclonedx.Component) string {
+	return strings.Join([]string{comp.BOMRef, string(comp.Type), comp.Group, comp.Name, comp.Version, comp.PackageURL}, "\x00")
+}
+
+// sortProperties sorts properties by name and value.
+func sortProperties(props *[]cyclonedx.Property) {
+	if props == nil {
+		return
+	}
+	sort.SliceStable(*props, func(i, j int) bool {
+		a, b := (*props)[i], (*props)[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Value < b.Value
+	})
+}
+
+// compositionKey orders compositions by aggregate and their references.
+func compositionKey(composition cyclonedx.Composition) string {
+	var refs []string
+	for _, list := range []*[]cyclonedx.BOMReference{composition.Assemblies, composition.Dependencies} {
+		if list == nil {
+			continue
+		}
+		for _, ref := range *list {
+			refs = append(refs, string(ref))
+		}
+	}
+	return string(composition.Aggregate) + "\x00" + strings.Join(refs, "\x00")
+}
@@ -0,0 +1,89 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+func TestReproducibleOutputIsByteIdentical(t *testing.T) {
+	bom := func(shuffle bool) *cyclonedx.BOM {
+		order := func(s any) {
+			if !shuffle {
+				return
+			}
+			switch list := s.(type) {
+			case []cyclonedx.Component:
+				slices.Reverse(list)
+			case []cyclonedx.Dependency:
+				slices.Reverse(list)
+			case []cyclonedx.Property:
+				slices.Reverse(list)
+			case []string:
+				slices.Reverse(list)
+			}
+		}
+		packages := []cyclonedx.Component{
+			{Type: cyclonedx.ComponentTypeLibrary, Name: "zlib", Version: "1.3", PackageURL: "pkg:apk/alpine/zlib@1.3", BOMRef: "zlib"},
+			{Type: cyclonedx.ComponentTypeLibrary, Name: "musl", Version: "1.2.5", PackageURL: "pkg:apk/alpine/musl@1.2.5", BOMRef: "musl"},
+			{Type: cyclonedx.ComponentTypeLibrary, Name: "openssl", Version: "3.3.2", PackageURL: "pkg:apk/alpine/openssl@3.3.2", BOMRef: "openssl"},
+		}
+		props := []cyclonedx.Property{{Name: "ocm:label:team", Value: "platform"}, {Name: "ocm:label:env", Value: "prod"}, {Name: "ocm:label:env", Value: "dev"}}
+		refs := []string{"zlib", "musl", "openssl"}
+		deps := []cyclonedx.Dependency{
+			{Ref: "app", Dependencies: &[]string{"image"}},
+			{Ref: "image", Dependencies: &refs},
+			{Ref: "openssl", Dependencies: &[]string{"zlib", "musl"}},
+		}
+		order(packages)
+		order(props)
+		order(refs)
+		order((*deps[2].Dependencies))
+		order(deps)
+
+		b := cyclonedx.NewBOM()
+		b.SerialNumber = "urn:uuid:" + map[bool]string{false: "11111111-1111-4111-8111-111111111111", true: "22222222-2222-4222-8222-222222222222"}[shuffle]
+		b.Metadata = &cyclonedx.Metadata{
+			Timestamp: map[bool]string{false: "2026-01-01T00:00:00Z", true: "2026-02-02T00:00:00Z"}[shuffle],
+			Component: &cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/app", Version: "1.0.0", BOMRef: "app", Properties: &props},
+		}
+		b.Components = &[]cyclonedx.Component{{Type: cyclonedx.ComponentTypeContainer, Name: "image", BOMRef: "image", Components: &packages}}
+		b.Dependencies = &deps
+		return b
+	}
+
+	formats := []SBOMFormat{FormatCycloneDXJSON, FormatSPDXJSON, FormatSPDX3JSON}
+	render := func(shuffle bool) map[SBOMFormat][]byte {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.json")
+		if err := NewCycloneDXProcessor().Write(bom(shuffle), path, 0); err != nil {
+			t.Fatal(err)
+		}
+		c := &CLIConverter{TempDir: dir, Reproducible: true, mergeTool: "native"}
+		subject := componentSBOM{Name: "acme.org/app", Version: "1.0.0", Path: path, CreationTime: "2025-03-01T12:00:00Z"}
+		rendered, err := c.renderSBOM(subject, path, formats)
+		if err != nil {
+			t.Fatalf("renderSBOM: %v", err)
+		}
+		return rendered
+	}
+
+	t.Setenv(SourceDateEpochEnv, "")
+	first, second := render(false), render(true)
+	for _, format := range formats {
+		if len(first[format]) == 0 {
+			t.Errorf("no %s rendering", format)
+		} else if !bytes.Equal(first[format], second[format]) {
+			t.Errorf("%s renderings differ:\n%s\n---\n%s", format, first[format], second[format])
+		}
+	}
+}
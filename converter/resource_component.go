@@ -0,0 +1,166 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/anchore/packageurl-go"
+	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
+)
+
+// resourcePropertyPrefix starts the names of the properties describing the OCM resource of a
+// container component: name, version, type, relation, imageReference, extraIdentity:<key> and
+// digest:normalisationAlgorithm.
+const resourcePropertyPrefix = "ocm:resource:"
+
+// resourceExtraIdentityPrefix starts the properties holding the extra identity of a resource.
+const resourceExtraIdentityPrefix = resourcePropertyPrefix + "extraIdentity:"
+
+// ApplyOCMResourceComponent turns the artifact Syft scanned into the node of the OCM resource it
+// was scanned for: a container component named after the resource, with a pkg:oci purl for the
+// image if its digest is known, the descriptor digest as hash and the resource identity as
+// properties. The packages found by Syft stay beneath it.
+func ApplyOCMResourceComponent(bom *cyclonedx.BOM, resource runtime.Resource, imageRef string) {
+	if bom == nil || bom.Metadata == nil || bom.Metadata.Component == nil {
+		return
+	}
+	node := bom.Metadata.Component
+	node.Type = cyclonedx.ComponentTypeContainer
+	node.Name = resource.Name
+	node.Version = resource.Version
+	node.PackageURL = ociPackageURL(imageRef, resourceImageDigest(resource, imageRef))
+	if hash, ok := resourceDigestHash(resource); ok {
+		node.Hashes = &[]cyclonedx.Hash{hash}
+	}
+
+	props := []cyclonedx.Property{
+		{Name: resourcePropertyPrefix + "name", Value: resource.Name},
+	}
+	if resource.Version != "" {
+		props = append(props, cyclonedx.Property{Name: resourcePropertyPrefix + "version", Value: resource.Version})
+	}
+	props = append(props, cyclonedx.Property{Name: resourcePropertyPrefix + "type", Value: resource.Type})
+	if resource.Relation != "" {
+		props = append(props, cyclonedx.Property{Name: resourcePropertyPrefix + "relation", Value: string(resource.Relation)})
+	}
+	keys := make([]string, 0, len(resource.ExtraIdentity))
+	for key := range resource.ExtraIdentity {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		props = append(props, cyclonedx.Property{Name: resourceExtraIdentityPrefix + key, Value: resource.ExtraIdentity[key]})
+	}
+	if imageRef != "" {
+		props = append(props, cyclonedx.Property{Name: resourcePropertyPrefix + "imageReference", Value: imageRef})
+	}
+	if resource.Digest != nil && resource.Digest.NormalisationAlgorithm != "" {
+		props = append(props, cyclonedx.Property{Name: resourcePropertyPrefix + "digest:normalisationAlgorithm", Value: resource.Digest.NormalisationAlgorithm})
+	}
+
+	if node.Properties == nil {
+		node.Properties = &[]cyclonedx.Property{}
+	}
+	*node.Properties = append(*node.Properties, props...)
+}
+
+// resourceProperty returns the value of an ocm:resource property of a component.
+func resourceProperty(comp *cyclonedx.Component, key string) string {
+	if comp == nil || comp.Properties == nil {
+		return ""
+	}
+	for _, prop := range *comp.Properties {
+		if prop.Name == resourcePropertyPrefix+key {
+			return prop.Value
+		}
+	}
+	return ""
+}
+
+// resourceExtraIdentity returns the extra identity of a resource component as "[key=value,...]",
+// or "" if it has none.
+func resourceExtraIdentity(comp *cyclonedx.Component) string {
+	if comp == nil || comp.Properties == nil {
+		return ""
+	}
+	var pairs []string
+	for _, prop := range *comp.Properties {
+		if key, ok := strings.CutPrefix(prop.Name, resourceExtraIdentityPrefix); ok {
+			pairs = append(pairs, key+"="+prop.Value)
+		}
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	sort.Strings(pairs)
+	return "[" + strings.Join(pairs, ",") + "]"
+}
+
+// splitImageReference splits an image reference into repository, tag and digest.
+func splitImageReference(ref string) (repository, tag, digest string) {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref, digest = ref[:i], ref[i+1:]
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref, tag = ref[:i], ref[i+1:]
+	}
+	return ref, tag, digest
+}
+
+// resourceImageDigest returns the manifest digest of an image resource: the digest pinned in the
+// image reference or, for OCI artifact digests, the descriptor digest.
+func resourceImageDigest(resource runtime.Resource, imageRef string) string {
+	if _, _, digest := splitImageReference(imageRef); digest != "" {
+		return digest
+	}
+	if d := resource.Digest; d != nil && d.Value != "" && strings.HasPrefix(d.NormalisationAlgorithm, "ociArtifactDigest") {
+		return strings.ToLower(strings.ReplaceAll(d.HashAlgorithm, "-", "") + ":" + d.Value)
+	}
+	return ""
+}
+
+// ociPackageURL returns the pkg:oci purl of an image, e.g.
+// "pkg:oci/app@sha256%3A...?repository_url=ghcr.io%2Facme%2Fapp&tag=1.0", or "" without a digest,
+// as the version of a pkg:oci purl is the manifest digest.
+func ociPackageURL(imageRef, digest string) string {
+	if imageRef == "" || digest == "" {
+		return ""
+	}
+	repository, tag, _ := splitImageReference(imageRef)
+	qualifiers := map[string]string{"repository_url": repository}
+	if tag != "" {
+		qualifiers["tag"] = tag
+	}
+	purl := packageurl.NewPackageURL("oci", "", strings.ToLower(path.Base(repository)), digest, packageurl.QualifiersFromMap(qualifiers), "")
+	return purl.ToString()
+}
+
+// resourceDigestHash maps the descriptor digest of a resource to a CycloneDX hash.
+func resourceDigestHash(resource runtime.Resource) (cyclonedx.Hash, bool) {
+	d := resource.Digest
+	if d == nil || d.Value == "" {
+		return cyclonedx.Hash{}, false
+	}
+	var algorithm cyclonedx.HashAlgorithm
+	switch strings.ToUpper(strings.ReplaceAll(d.HashAlgorithm, "-", "")) {
+	case "SHA1":
+		algorithm = cyclonedx.HashAlgoSHA1
+	case "SHA256":
+		algorithm = cyclonedx.HashAlgoSHA256
+	case "SHA384":
+		algorithm = cyclonedx.HashAlgoSHA384
+	case "SHA512":
+		algorithm = cyclonedx.HashAlgoSHA512
+	default:
+		return cyclonedx.Hash{}, false
+	}
+	return cyclonedx.Hash{Algorithm: algorithm, Value: strings.ToLower(d.Value)}, true
+}
@@ -0,0 +1,114 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
+	ocmruntime "ocm.software/open-component-model/bindings/go/runtime"
+)
+
+func TestApplyOCMResourceComponent(t *testing.T) {
+	const manifest = "3b2f0c4a"
+	tests := []struct {
+		name       string
+		imageRef   string
+		digest     *runtime.Digest
+		wantPURL   string
+		wantHashes []cyclonedx.Hash
+	}{
+		{
+			name:     "digest pinned in the image reference",
+			imageRef: "ghcr.io/acme/App:1.0.0@sha256:" + manifest,
+			wantPURL: "pkg:oci/app@sha256%3A" + manifest + "?repository_url=ghcr.io%2Facme%2FApp&tag=1.0.0",
+		},
+		{
+			name:       "OCI artifact digest",
+			imageRef:   "ghcr.io/acme/app:1.0.0",
+			digest:     &runtime.Digest{HashAlgorithm: "SHA-256", NormalisationAlgorithm: "ociArtifactDigest/v1", Value: manifest},
+			wantPURL:   "pkg:oci/app@sha256%3A" + manifest + "?repository_url=ghcr.io%2Facme%2Fapp&tag=1.0.0",
+			wantHashes: []cyclonedx.Hash{{Algorithm: cyclonedx.HashAlgoSHA256, Value: manifest}},
+		},
+		{
+			// A purl without its digest version would identify no particular image
+			name:     "tag only",
+			imageRef: "ghcr.io/acme/app:1.0.0",
+		},
+		{
+			name:       "digest of another normalisation",
+			imageRef:   "ghcr.io/acme/app:1.0.0",
+			digest:     &runtime.Digest{HashAlgorithm: "SHA-256", NormalisationAlgorithm: "genericBlobDigest/v1", Value: "AB12"},
+			wantHashes: []cyclonedx.Hash{{Algorithm: cyclonedx.HashAlgoSHA256, Value: "ab12"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := runtime.Resource{Type: "ociImage", Relation: runtime.ExternalRelation, Digest: tt.digest}
+			resource.Name = "frontend"
+			resource.Version = "1.0.0"
+			bom := cyclonedx.NewBOM()
+			bom.Metadata = &cyclonedx.Metadata{Component: &cyclonedx.Component{Type: cyclonedx.ComponentTypeContainer, Name: tt.imageRef, BOMRef: "image"}}
+
+			ApplyOCMResourceComponent(bom, resource, tt.imageRef)
+			node := bom.Metadata.Component
+			if node.Name != "frontend" || node.Version != "1.0.0" || node.Type != cyclonedx.ComponentTypeContainer {
+				t.Errorf("node = %s %s (%s), want the container frontend 1.0.0", node.Name, node.Version, node.Type)
+			}
+			if node.PackageURL != tt.wantPURL {
+				t.Errorf("purl = %q, want %q", node.PackageURL, tt.wantPURL)
+			}
+			var hashes []cyclonedx.Hash
+			if node.Hashes != nil {
+				hashes = *node.Hashes
+			}
+			if !slices.Equal(hashes, tt.wantHashes) {
+				t.Errorf("hashes = %+v, want %+v", hashes, tt.wantHashes)
+			}
+		})
+	}
+}
+
+func TestApplyOCMResourceComponentProperties(t *testing.T) {
+	resource := runtime.Resource{
+		Type:     "ociImage",
+		Relation: runtime.LocalRelation,
+		Digest:   &runtime.Digest{HashAlgorithm: "SHA-256", NormalisationAlgorithm: "ociArtifactDigest/v1", Value: "ab12"},
+	}
+	resource.Name = "frontend"
+	resource.ExtraIdentity = ocmruntime.Identity{"os": "linux", "arch": "arm64"}
+	bom := cyclonedx.NewBOM()
+	bom.Metadata = &cyclonedx.Metadata{Component: &cyclonedx.Component{
+		Name:       "ghcr.io/acme/app:1.0.0",
+		Properties: &[]cyclonedx.Property{{Name: "syft:image:labels:vendor", Value: "ACME"}},
+	}}
+
+	ApplyOCMResourceComponent(bom, resource, "ghcr.io/acme/app:1.0.0")
+	var got []string
+	for _, p := range *bom.Metadata.Component.Properties {
+		got = append(got, p.Name+"="+p.Value)
+	}
+	// Properties of Syft are kept, a resource without version has no version property
+	want := []string{
+		"syft:image:labels:vendor=ACME",
+		"ocm:resource:name=frontend",
+		"ocm:resource:type=ociImage",
+		"ocm:resource:relation=local",
+		"ocm:resource:extraIdentity:arch=arm64",
+		"ocm:resource:extraIdentity:os=linux",
+		"ocm:resource:imageReference=ghcr.io/acme/app:1.0.0",
+		"ocm:resource:digest:normalisationAlgorithm=ociArtifactDigest/v1",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("properties =\n%q\nwant\n%q", got, want)
+	}
+	if extra := resourceExtraIdentity(bom.Metadata.Component); extra != "[arch=arm64,os=linux]" {
+		t.Errorf("extra identity = %q, want [arch=arm64,os=linux]", extra)
+	}
+}
@@ -0,0 +1,166 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"fmt"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
+)
+
+// signaturePropertyPrefix starts the names of the properties describing the signatures of a
+// component version: "ocm:signature:<name>:<field>" for digest, hashAlgorithm,
+// normalisationAlgorithm, algorithm, value, mediaType and issuer.
+const signaturePropertyPrefix = "ocm:signature:"
+
+// componentSignatures are the signatures of a component version.
+type componentSignatures struct {
+	Name       string
+	Version    string
+	Signatures []runtime.Signature
+}
+
+// recordSignatures remembers the signatures of a component version for the declarations of the
+// final SBOM.
+func (c *CLIConverter) recordSignatures(descriptor *runtime.Descriptor) {
+	if len(descriptor.Signatures) == 0 {
+		return
+	}
+	c.signatures = append(c.signatures, componentSignatures{
+		Name:       descriptor.Component.Name,
+		Version:    descriptor.Component.Version,
+		Signatures: descriptor.Signatures,
+	})
+}
+
+// SignatureProperties describes the signatures of a component version as properties.
+func SignatureProperties(signatures []runtime.Signature) []cyclonedx.Property {
+	var props []cyclonedx.Property
+	for _, sig := range signatures {
+		prefix := signaturePropertyPrefix + sig.Name + ":"
+		add := func(field, value string) {
+			if value != "" {
+				props = append(props, cyclonedx.Property{Name: prefix + field, Value: value})
+			}
+		}
+		add("digest", sig.Digest.Value)
+		add("hashAlgorithm", sig.Digest.HashAlgorithm)
+		add("normalisationAlgorithm", sig.Digest.NormalisationAlgorithm)
+		add("algorithm", sig.Signature.Algorithm)
+		add("value", sig.Signature.Value)
+		add("mediaType", sig.Signature.MediaType)
+		add("issuer", sig.Signature.Issuer)
+	}
+	return props
+}
+
+// AddSignatureDeclarations records the signatures of the component versions as CycloneDX
+// declarations: every signature becomes evidence for a claim that the component node was signed,
+// and the claims of each issuer are attested by that issuer as assessor. Signatures of
+// component versions without a node in the BOM are skipped.
+func AddSignatureDeclarations(bom *cyclonedx.BOM, signed []componentSignatures) {
+	if bom == nil || len(signed) == 0 {
+		return
+	}
+
+	var assessors []cyclonedx.Assessor
+	var evidence []cyclonedx.DeclarationEvidence
+	var claims []cyclonedx.Claim
+	var issuers []string                                  // issuers in order of appearance; "" for unknown
+	claimsOf := make(map[string][]cyclonedx.BOMReference) // issuer -> claim refs
+
+	for _, cs := range signed {
+		node := findComponentNode(bom, cs.Name, cs.Version)
+		if node == nil {
+			continue
+		}
+		for _, sig := range cs.Signatures {
+			id := fmt.Sprintf("ocm:signature/%s@%s/%s", cs.Name, cs.Version, sig.Name)
+			issuer := sig.Signature.Issuer
+
+			data := []cyclonedx.EvidenceData{
+				{Name: "digest", Contents: &cyclonedx.EvidenceDataContents{Attachment: &cyclonedx.AttachedText{
+					Content: fmt.Sprintf("%s:%s", sig.Digest.HashAlgorithm, sig.Digest.Value),
+				}}},
+				{Name: "signature", Contents: &cyclonedx.EvidenceDataContents{Attachment: &cyclonedx.AttachedText{
+					Content:     sig.Signature.Value,
+					ContentType: sig.Signature.MediaType,
+				}}},
+			}
+			ev := cyclonedx.DeclarationEvidence{
+				BOMRef:       id + "/evidence",
+				PropertyName: signaturePropertyPrefix + sig.Name,
+				Description: fmt.Sprintf("%s signature %q of %s:%s over the descriptor digest normalised with %s",
+					sig.Signature.Algorithm, sig.Name, cs.Name, cs.Version, sig.Digest.NormalisationAlgorithm),
+				Data: &data,
+			}
+			if issuer != "" {
+				ev.Author = &cyclonedx.OrganizationalContact{Name: issuer}
+			}
+			evidence = append(evidence, ev)
+
+			predicate := "The component version descriptor is signed"
+			if issuer != "" {
+				predicate += " by " + issuer
+			}
+			claims = append(claims, cyclonedx.Claim{
+				BOMRef:    id,
+				Target:    cyclonedx.BOMReference(node.BOMRef),
+				Predicate: predicate,
+				Evidence:  &[]cyclonedx.BOMReference{cyclonedx.BOMReference(ev.BOMRef)},
+			})
+
+			if _, seen := claimsOf[issuer]; !seen {
+				issuers = append(issuers, issuer)
+			}
+			claimsOf[issuer] = append(claimsOf[issuer], cyclonedx.BOMReference(id))
+		}
+	}
+	if len(claims) == 0 {
+		return
+	}
+
+	var attestations []cyclonedx.Attestation
+	for _, issuer := range issuers {
+		refs := claimsOf[issuer]
+		attestation := cyclonedx.Attestation{
+			Summary: "OCM component version signatures",
+			Map:     &[]cyclonedx.AttestationMap{{Claims: &refs}},
+		}
+		if issuer != "" {
+			assessor := cyclonedx.Assessor{
+				BOMRef:       cyclonedx.BOMReference("ocm:signature-issuer/" + issuer),
+				Organization: &cyclonedx.OrganizationalEntity{Name: issuer},
+			}
+			assessors = append(assessors, assessor)
+			attestation.Summary += " issued by " + issuer
+			attestation.Assessor = assessor.BOMRef
+		}
+		attestations = append(attestations, attestation)
+	}
+
+	if bom.Declarations == nil {
+		bom.Declarations = &cyclonedx.Declarations{}
+	}
+	d := bom.Declarations
+	if len(assessors) > 0 {
+		d.Assessors = appendSlice(d.Assessors, assessors)
+	}
+	d.Attestations = appendSlice(d.Attestations, attestations)
+	d.Claims = appendSlice(d.Claims, claims)
+	d.Evidence = appendSlice(d.Evidence, evidence)
+}
+
+// appendSlice appends items to an optional slice.
+func appendSlice[T any](list *[]T, items []T) *[]T {
+	if list == nil {
+		return &items
+	}
+	*list = append(*list, items...)
+	return list
+}
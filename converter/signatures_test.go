@@ -0,0 +1,124 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
+)
+
+// testSignature returns a signature of name issued by issuer.
+func testSignature(name, issuer string) runtime.Signature {
+	return runtime.Signature{
+		Name:      name,
+		Digest:    runtime.Digest{HashAlgorithm: "SHA-256", NormalisationAlgorithm: "jsonNormalisation/v4alpha1", Value: "ab12"},
+		Signature: runtime.SignatureInfo{Algorithm: SignatureAlgorithmRSAPKCS1v15, Value: "cd34", MediaType: "application/vnd.ocm.signature.rsa", Issuer: issuer},
+	}
+}
+
+func TestSignatureProperties(t *testing.T) {
+	sig := testSignature("release", "")
+	var got []string
+	for _, p := range SignatureProperties([]runtime.Signature{sig}) {
+		got = append(got, p.Name+"="+p.Value)
+	}
+	// Empty fields such as the issuer are left out
+	want := []string{
+		"ocm:signature:release:digest=ab12",
+		"ocm:signature:release:hashAlgorithm=SHA-256",
+		"ocm:signature:release:normalisationAlgorithm=jsonNormalisation/v4alpha1",
+		"ocm:signature:release:algorithm=" + SignatureAlgorithmRSAPKCS1v15,
+		"ocm:signature:release:value=cd34",
+		"ocm:signature:release:mediaType=application/vnd.ocm.signature.rsa",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("properties =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestAddSignatureDeclarations(t *testing.T) {
+	bom := cyclonedx.NewBOM()
+	bom.Metadata = &cyclonedx.Metadata{Component: &cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/app", Version: "1.0.0", BOMRef: "app"}}
+	bom.Components = &[]cyclonedx.Component{
+		// An image of the same name is not the component version
+		{Type: cyclonedx.ComponentTypeContainer, Name: "acme.org/lib", Version: "2.0.0", BOMRef: "image"},
+		{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/lib", Version: "2.0.0", BOMRef: "lib"},
+	}
+
+	AddSignatureDeclarations(bom, []componentSignatures{
+		{Name: "acme.org/app", Version: "1.0.0", Signatures: []runtime.Signature{testSignature("release", "ACME"), testSignature("nightly", "")}},
+		{Name: "acme.org/lib", Version: "2.0.0", Signatures: []runtime.Signature{testSignature("release", "ACME")}},
+		{Name: "acme.org/gone", Version: "1.0.0", Signatures: []runtime.Signature{testSignature("release", "ACME")}},
+	})
+	d := bom.Declarations
+	if d == nil || d.Claims == nil || d.Evidence == nil || d.Attestations == nil || d.Assessors == nil {
+		t.Fatalf("declarations = %+v, want claims, evidence, attestations and assessors", d)
+	}
+
+	// Every signature of a component with a node is a claim on that node
+	targets := make(map[string]string)
+	for _, claim := range *d.Claims {
+		targets[claim.BOMRef] = string(claim.Target)
+	}
+	wantTargets := map[string]string{
+		"ocm:signature/acme.org/app@1.0.0/release": "app",
+		"ocm:signature/acme.org/app@1.0.0/nightly": "app",
+		"ocm:signature/acme.org/lib@2.0.0/release": "lib",
+	}
+	if len(targets) != len(wantTargets) {
+		t.Errorf("claims target %v, want %v", targets, wantTargets)
+	}
+	for ref, target := range wantTargets {
+		if targets[ref] != target {
+			t.Errorf("claim %s targets %q, want %q", ref, targets[ref], target)
+		}
+	}
+
+	evidence := make(map[string]cyclonedx.DeclarationEvidence)
+	for _, ev := range *d.Evidence {
+		evidence[ev.BOMRef] = ev
+	}
+	for _, claim := range *d.Claims {
+		if claim.Evidence == nil || len(*claim.Evidence) != 1 {
+			t.Fatalf("evidence of claim %s = %v, want one", claim.BOMRef, claim.Evidence)
+		}
+		ev, ok := evidence[string((*claim.Evidence)[0])]
+		if !ok {
+			t.Errorf("evidence %s of claim %s does not resolve", (*claim.Evidence)[0], claim.BOMRef)
+			continue
+		}
+		if ev.Data == nil || len(*ev.Data) != 2 || (*ev.Data)[0].Contents.Attachment.Content != "SHA-256:ab12" {
+			t.Errorf("evidence data of %s = %+v, want digest and signature", ev.BOMRef, ev.Data)
+		}
+	}
+
+	// One attestation per issuer, the known issuer as assessor
+	if len(*d.Assessors) != 1 || (*d.Assessors)[0].BOMRef != "ocm:signature-issuer/ACME" || (*d.Assessors)[0].Organization.Name != "ACME" {
+		t.Errorf("assessors = %+v, want ACME", *d.Assessors)
+	}
+	if len(*d.Attestations) != 2 {
+		t.Fatalf("attestations = %+v, want one of ACME and one without issuer", *d.Attestations)
+	}
+	acme, unknown := (*d.Attestations)[0], (*d.Attestations)[1]
+	if acme.Assessor != "ocm:signature-issuer/ACME" || !slices.Equal(*(*acme.Map)[0].Claims, []cyclonedx.BOMReference{"ocm:signature/acme.org/app@1.0.0/release", "ocm:signature/acme.org/lib@2.0.0/release"}) {
+		t.Errorf("attestation of ACME = %+v with claims %v", acme, *(*acme.Map)[0].Claims)
+	}
+	if unknown.Assessor != "" || !slices.Equal(*(*unknown.Map)[0].Claims, []cyclonedx.BOMReference{"ocm:signature/acme.org/app@1.0.0/nightly"}) {
+		t.Errorf("attestation without issuer = %+v with claims %v", unknown, *(*unknown.Map)[0].Claims)
+	}
+}
+
+func TestAddSignatureDeclarationsWithoutNodes(t *testing.T) {
+	bom := cyclonedx.NewBOM()
+	AddSignatureDeclarations(bom, []componentSignatures{{Name: "acme.org/gone", Version: "1.0.0", Signatures: []runtime.Signature{testSignature("release", "ACME")}}})
+	if bom.Declarations != nil {
+		t.Errorf("declarations = %+v, want none", bom.Declarations)
+	}
+}
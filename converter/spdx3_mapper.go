@@ -0,0 +1,306 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+const (
+	// SPDX3SpecVersion is the SPDX 3 version written by the spdx3-json format.
+	SPDX3SpecVersion = "3.0.1"
+
+	// SPDX3Context is the JSON-LD context of SPDX 3 documents.
+	SPDX3Context = "https://spdx.org/rdf/3.0.1/spdx-context.jsonld"
+
+	spdx3CreationInfoID = "_:creationinfo"
+)
+
+// SPDX3Document is an SPDX 3 JSON-LD document: a context and a graph of elements.
+type SPDX3Document struct {
+	Context string `json:"@context"`
+	Graph   []any  `json:"@graph"`
+}
+
+// SPDX3CreationInfo is shared by all elements of a document.
+type SPDX3CreationInfo struct {
+	Type         string   `json:"type"`
+	ID           string   `json:"@id"`
+	SpecVersion  string   `json:"specVersion"`
+	Created      string   `json:"created"`
+	CreatedBy    []string `json:"createdBy"`
+	CreatedUsing []string `json:"createdUsing,omitempty"`
+}
+
+// SPDX3Element holds the properties common to agents, tools and documents.
+type SPDX3Element struct {
+	Type         string   `json:"type"`
+	SPDXID       string   `json:"spdxId"`
+	CreationInfo string   `json:"creationInfo"`
+	Name         string   `json:"name,omitempty"`
+	Comment      string   `json:"comment,omitempty"`
+	RootElement  []string `json:"rootElement,omitempty"`
+	Element      []string `json:"element,omitempty"`
+	Profile      []string `json:"profileConformance,omitempty"`
+	SbomType     []string `json:"software_sbomType,omitempty"`
+}
+
+// SPDX3Package is a software_Package element.
+type SPDX3Package struct {
+	Type               string                    `json:"type"`
+	SPDXID             string                    `json:"spdxId"`
+	CreationInfo       string                    `json:"creationInfo"`
+	Name               string                    `json:"name"`
+	Description        string                    `json:"description,omitempty"`
+	Comment            string                    `json:"comment,omitempty"`
+	SuppliedBy         string                    `json:"suppliedBy,omitempty"`
+	VerifiedUsing      []SPDX3Hash               `json:"verifiedUsing,omitempty"`
+	ExternalIdentifier []SPDX3ExternalIdentifier `json:"externalIdentifier,omitempty"`
+	PackageVersion     string                    `json:"software_packageVersion,omitempty"`
+	PackageURL         string                    `json:"software_packageUrl,omitempty"`
+	DownloadLocation   string                    `json:"software_downloadLocation,omitempty"`
+	PrimaryPurpose     string                    `json:"software_primaryPurpose,omitempty"`
+}
+
+// SPDX3Hash is an integrity method of an element.
+type SPDX3Hash struct {
+	Type      string `json:"type"`
+	Algorithm string `json:"algorithm"`
+	HashValue string `json:"hashValue"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+// SPDX3ExternalIdentifier identifies an element outside of SPDX, e.g. by CPE.
+type SPDX3ExternalIdentifier struct {
+	Type                   string `json:"type"`
+	ExternalIdentifierType string `json:"externalIdentifierType"`
+	Identifier             string `json:"identifier"`
+}
+
+// SPDX3LicenseExpression is a simplelicensing_LicenseExpression element.
+type SPDX3LicenseExpression struct {
+	Type              string `json:"type"`
+	SPDXID            string `json:"spdxId"`
+	CreationInfo      string `json:"creationInfo"`
+	LicenseExpression string `json:"simplelicensing_licenseExpression"`
+}
+
+// SPDX3Relationship relates one element to one or more others.
+type SPDX3Relationship struct {
+	Type             string   `json:"type"`
+	SPDXID           string   `json:"spdxId"`
+	CreationInfo     string   `json:"creationInfo"`
+	From             string   `json:"from"`
+	RelationshipType string   `json:"relationshipType"`
+	To               []string `json:"to"`
+}
+
+// CycloneDXToSPDX3 maps a merged CycloneDX BOM to an SPDX 3 JSON-LD document. Components and
+// resources become software_Package elements, the reference tree becomes contains and dependsOn
+// relationships and the toolchain is recorded in the shared CreationInfo.
+func CycloneDXToSPDX3(bom *cyclonedx.BOM) (*SPDX3Document, error) {
+	// The SPDX 2.3 mapping already resolves identifiers and the relationship graph
+	doc23, err := CycloneDXToSPDX23(bom)
+	if err != nil {
+		return nil, err
+	}
+
+	ns := doc23.DocumentNamespace + "#"
+	id := func(elementID string) string { return ns + "SPDXRef-" + elementID }
+
+	creation := &SPDX3CreationInfo{
+		Type:        "CreationInfo",
+		ID:          spdx3CreationInfoID,
+		SpecVersion: SPDX3SpecVersion,
+		Created:     doc23.CreationInfo.Created,
+	}
+	graph := []any{creation}
+
+	agent := SPDX3Element{Type: "SoftwareAgent", SPDXID: id("Agent-" + ToolName), CreationInfo: spdx3CreationInfoID, Name: ToolName}
+	creation.CreatedBy = []string{agent.SPDXID}
+	graph = append(graph, agent)
+	for i, creator := range doc23.CreationInfo.Creators {
+		if creator.CreatorType != "Tool" {
+			continue
+		}
+		tool := SPDX3Element{Type: "Tool", SPDXID: id(fmt.Sprintf("Tool-%d", i+1)), CreationInfo: spdx3CreationInfoID, Name: creator.Creator}
+		creation.CreatedUsing = append(creation.CreatedUsing, tool.SPDXID)
+		graph = append(graph, tool)
+	}
+
+	var elements, roots []string
+	suppliers := make(map[string]string)
+	var relationships []*SPDX3Relationship
+	relationshipIndex := make(map[string]*SPDX3Relationship)
+	relate := func(from, relationshipType string, to ...string) {
+		// Relationships of the same type from the same element share one element
+		key := from + "\x00" + relationshipType
+		if rel, ok := relationshipIndex[key]; ok {
+			rel.To = append(rel.To, to...)
+			return
+		}
+		rel := &SPDX3Relationship{
+			Type:             "Relationship",
+			CreationInfo:     spdx3CreationInfoID,
+			From:             from,
+			RelationshipType: relationshipType,
+			To:               to,
+		}
+		relationshipIndex[key] = rel
+		relationships = append(relationships, rel)
+	}
+
+	for _, pkg := range doc23.Packages {
+		p := SPDX3Package{
+			Type:           "software_Package",
+			SPDXID:         id(string(pkg.PackageSPDXIdentifier)),
+			CreationInfo:   spdx3CreationInfoID,
+			Name:           pkg.PackageName,
+			Description:    pkg.PackageDescription,
+			Comment:        pkg.PackageComment,
+			PackageVersion: pkg.PackageVersion,
+			PrimaryPurpose: spdx3Purpose(pkg.PrimaryPackagePurpose),
+		}
+		if pkg.PackageDownloadLocation != spdxNoAssertion {
+			p.DownloadLocation = pkg.PackageDownloadLocation
+		}
+		if pkg.PackageSupplier != nil && pkg.PackageSupplier.Supplier != "" {
+			name := pkg.PackageSupplier.Supplier
+			if _, ok := suppliers[name]; !ok {
+				org := SPDX3Element{Type: "Organization", SPDXID: id(fmt.Sprintf("Organization-%d", len(suppliers)+1)), CreationInfo: spdx3CreationInfoID, Name: name}
+				suppliers[name] = org.SPDXID
+				graph = append(graph, org)
+				elements = append(elements, org.SPDXID)
+			}
+			p.SuppliedBy = suppliers[name]
+		}
+		for _, checksum := range pkg.PackageChecksums {
+			p.VerifiedUsing = append(p.VerifiedUsing, spdx3Hash(checksum))
+		}
+		for _, ref := range pkg.PackageExternalReferences {
+			switch ref.RefType {
+			case common.TypePackageManagerPURL:
+				p.PackageURL = ref.Locator
+			case common.TypeSecurityCPE23Type:
+				p.ExternalIdentifier = append(p.ExternalIdentifier, SPDX3ExternalIdentifier{Type: "ExternalIdentifier", ExternalIdentifierType: "cpe23", Identifier: ref.Locator})
+			}
+		}
+		graph = append(graph, p)
+		elements = append(elements, p.SPDXID)
+
+		if pkg.PackageLicenseDeclared != "" && pkg.PackageLicenseDeclared != spdxNoAssertion {
+			license := SPDX3LicenseExpression{
+				Type:              "simplelicensing_LicenseExpression",
+				SPDXID:            id("License-" + string(pkg.PackageSPDXIdentifier)),
+				CreationInfo:      spdx3CreationInfoID,
+				LicenseExpression: pkg.PackageLicenseDeclared,
+			}
+			graph = append(graph, license)
+			elements = append(elements, license.SPDXID)
+			relate(p.SPDXID, "hasDeclaredLicense", license.SPDXID)
+		}
+	}
+
+	for _, rel := range doc23.Relationships {
+		from, to := id(string(rel.RefA.ElementRefID)), id(string(rel.RefB.ElementRefID))
+		switch rel.Relationship {
+		case common.TypeRelationshipDescribe:
+			roots = append(roots, to)
+		case common.TypeRelationshipContains:
+			relate(from, "contains", to)
+		case common.TypeRelationshipDependsOn:
+			relate(from, "dependsOn", to)
+		}
+	}
+	for i, rel := range relationships {
+		rel.SPDXID = id(fmt.Sprintf("Relationship-%d", i+1))
+		graph = append(graph, rel)
+		elements = append(elements, rel.SPDXID)
+	}
+
+	sbom := SPDX3Element{
+		Type:         "software_Sbom",
+		SPDXID:       id("SBOM"),
+		CreationInfo: spdx3CreationInfoID,
+		Name:         doc23.DocumentName,
+		RootElement:  roots,
+		Element:      elements,
+		SbomType:     []string{"build"},
+	}
+	document := SPDX3Element{
+		Type:         "SpdxDocument",
+		SPDXID:       id("DOCUMENT"),
+		CreationInfo: spdx3CreationInfoID,
+		Name:         doc23.DocumentName,
+		RootElement:  []string{sbom.SPDXID},
+		Element:      append([]string{sbom.SPDXID}, elements...),
+		Profile:      []string{"core", "software", "simpleLicensing"},
+	}
+	graph = append(graph, sbom, document)
+
+	return &SPDX3Document{Context: SPDX3Context, Graph: graph}, nil
+}
+
+// WriteSPDX3 writes an SPDX 3 document as JSON-LD.
+func WriteSPDX3(doc *SPDX3Document, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(doc)
+}
+
+// spdx3Purpose maps an SPDX 2.3 primary package purpose to its SPDX 3 software purpose.
+func spdx3Purpose(purpose string) string {
+	switch purpose {
+	case "":
+		return ""
+	case "OPERATING-SYSTEM":
+		return "operatingSystem"
+	case "APPLICATION", "CONTAINER", "DEVICE", "FILE", "FIRMWARE", "FRAMEWORK", "LIBRARY", "ARCHIVE", "INSTALL", "SOURCE":
+		return strings.ToLower(purpose)
+	default:
+		return "other"
+	}
+}
+
+// spdx3HashAlgorithms maps SPDX 2.3 checksum algorithms to the SPDX 3 HashAlgorithm vocabulary.
+var spdx3HashAlgorithms = map[common.ChecksumAlgorithm]string{
+	common.ADLER32:     "adler32",
+	common.BLAKE2b_256: "blake2b256",
+	common.BLAKE2b_384: "blake2b384",
+	common.BLAKE2b_512: "blake2b512",
+	common.BLAKE3:      "blake3",
+	common.MD2:         "md2",
+	common.MD4:         "md4",
+	common.MD5:         "md5",
+	common.MD6:         "md6",
+	common.SHA1:        "sha1",
+	common.SHA224:      "sha224",
+	common.SHA256:      "sha256",
+	common.SHA384:      "sha384",
+	common.SHA512:      "sha512",
+	common.SHA3_256:    "sha3_256",
+	common.SHA3_384:    "sha3_384",
+	common.SHA3_512:    "sha3_512",
+}
+
+// spdx3Hash maps an SPDX 2.3 checksum to an SPDX 3 hash; algorithms outside of the vocabulary
+// become "other" with the original name as comment.
+func spdx3Hash(checksum common.Checksum) SPDX3Hash {
+	hash := SPDX3Hash{Type: "Hash", Algorithm: spdx3HashAlgorithms[checksum.Algorithm], HashValue: checksum.Value}
+	if hash.Algorithm == "" {
+		hash.Algorithm = "other"
+		hash.Comment = string(checksum.Algorithm)
+	}
+	return hash
+}
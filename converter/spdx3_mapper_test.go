@@ -0,0 +1,278 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// Subset of the SPDX 3.0.1 model (https://spdx.org/rdf/3.0.1/spdx-model.ttl) covering the
+// classes and vocabularies the spdx3-json format writes.
+var (
+	spdx3ModelRelationshipTypes = strings.Fields(`affects amendedBy ancestorOf availableFrom configures
+		contains coordinatedBy copiedTo delegatedTo dependsOn descendantOf describes doesNotAffect expandsTo
+		exploitCreatedBy fixedBy fixedIn foundBy generates hasAddedFile hasAssessmentFor
+		hasAssociatedVulnerability hasConcludedLicense hasDataFile hasDeclaredLicense hasDeletedFile
+		hasDependencyManifest hasDistributionArtifact hasDocumentation hasDynamicLink hasEvidence hasExample
+		hasHost hasInput hasMetadata hasOptionalComponent hasOptionalDependency hasOutput hasPrerequisite
+		hasProvidedDependency hasRequirement hasSpecification hasStaticLink hasTest hasTestCase hasVariant
+		invokedBy modifiedBy other packagedBy patchedBy publishedBy reportedBy republishedBy
+		serializedInArtifact testedOn trainedOn underInvestigationFor usesTool`)
+	spdx3ModelHashAlgorithms = strings.Fields(`adler32 blake2b256 blake2b384 blake2b512 blake3
+		crystalsDilithium crystalsKyber falcon md2 md4 md5 md6 other sha1 sha224 sha256 sha384 sha3_224
+		sha3_256 sha3_384 sha3_512 sha512`)
+	spdx3ModelSoftwarePurposes = strings.Fields(`application archive bom configuration container data
+		device deviceDriver diskImage documentation evidence executable file filesystemImage firmware
+		framework install library manifest model module operatingSystem other patch platform requirement
+		source specification test`)
+	spdx3ModelProfiles = strings.Fields(`ai build core dataset expandedLicensing extension lite security
+		simpleLicensing software`)
+	spdx3ModelSbomTypes               = strings.Fields(`analyzed build deployed design runtime source`)
+	spdx3ModelExternalIdentifierTypes = strings.Fields(`cpe22 cpe23 cve email gitoid other packageUrl
+		securityOther swhid swid urlScheme`)
+
+	spdx3ElementProperties    = `type spdxId creationInfo name summary description comment verifiedUsing externalRef externalIdentifier extension`
+	spdx3CollectionProperties = spdx3ElementProperties + ` element rootElement profileConformance`
+	spdx3PackageProperties    = spdx3ElementProperties + ` originatedBy suppliedBy builtTime releaseTime validUntilTime
+		standardName supportLevel software_contentIdentifier software_primaryPurpose software_additionalPurpose
+		software_copyrightText software_attributionText software_downloadLocation software_homePage
+		software_packageUrl software_packageVersion software_sourceInfo`
+
+	// spdx3ModelClasses lists the properties of each class, inherited ones included, and which
+	// of them are required.
+	spdx3ModelClasses = map[string]struct{ properties, required string }{
+		"CreationInfo":                      {`type @id specVersion comment created createdBy createdUsing`, `specVersion created createdBy`},
+		"SoftwareAgent":                     {spdx3ElementProperties, `spdxId creationInfo`},
+		"Organization":                      {spdx3ElementProperties, `spdxId creationInfo`},
+		"Tool":                              {spdx3ElementProperties, `spdxId creationInfo`},
+		"software_Package":                  {spdx3PackageProperties, `spdxId creationInfo`},
+		"simplelicensing_LicenseExpression": {spdx3ElementProperties + ` simplelicensing_licenseExpression simplelicensing_customIdToUri simplelicensing_licenseListVersion`, `spdxId creationInfo simplelicensing_licenseExpression`},
+		"Relationship":                      {spdx3ElementProperties + ` from to relationshipType completeness startTime endTime`, `spdxId creationInfo from relationshipType`},
+		"software_Sbom":                     {spdx3CollectionProperties + ` software_sbomType`, `spdxId creationInfo`},
+		"SpdxDocument":                      {spdx3CollectionProperties + ` dataLicense import namespaceMap`, `spdxId creationInfo`},
+		"Hash":                              {`type algorithm hashValue comment`, `algorithm hashValue`},
+		"ExternalIdentifier":                {`type externalIdentifierType identifier comment identifierLocator issuingAuthority`, `externalIdentifierType identifier`},
+	}
+
+	spdx3DateTime    = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z$`)
+	spdx3SpecVersion = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+)
+
+// validateSPDX3Object checks an object's type, its properties against the model and the
+// vocabularies of its properties, and descends into nested hashes and identifiers.
+func validateSPDX3Object(t *testing.T, obj map[string]any) {
+	t.Helper()
+	typ, _ := obj["type"].(string)
+	class, ok := spdx3ModelClasses[typ]
+	if !ok {
+		t.Errorf("%v: type %q is not an SPDX 3.0.1 class written by the mapper", obj["spdxId"], typ)
+		return
+	}
+	allowed := strings.Fields(class.properties)
+	for key := range obj {
+		if !slices.Contains(allowed, key) {
+			t.Errorf("%s %v: property %q is not defined for the class", typ, obj["spdxId"], key)
+		}
+	}
+	for _, key := range strings.Fields(class.required) {
+		if v, ok := obj[key]; !ok || v == "" {
+			t.Errorf("%s %v: required property %q is missing", typ, obj["spdxId"], key)
+		}
+	}
+
+	vocabulary := func(key string, values []string) {
+		var got []any
+		switch v := obj[key].(type) {
+		case nil:
+			return
+		case []any:
+			got = v
+		default:
+			got = []any{v}
+		}
+		for _, value := range got {
+			if s, _ := value.(string); !slices.Contains(values, s) {
+				t.Errorf("%s %v: %s %q is not in the SPDX 3.0.1 vocabulary", typ, obj["spdxId"], key, value)
+			}
+		}
+	}
+	vocabulary("relationshipType", spdx3ModelRelationshipTypes)
+	vocabulary("algorithm", spdx3ModelHashAlgorithms)
+	vocabulary("software_primaryPurpose", spdx3ModelSoftwarePurposes)
+	vocabulary("profileConformance", spdx3ModelProfiles)
+	vocabulary("software_sbomType", spdx3ModelSbomTypes)
+	vocabulary("externalIdentifierType", spdx3ModelExternalIdentifierTypes)
+
+	for _, key := range []string{"verifiedUsing", "externalIdentifier"} {
+		nested, _ := obj[key].([]any)
+		for _, n := range nested {
+			validateSPDX3Object(t, n.(map[string]any))
+		}
+	}
+}
+
+func TestCycloneDXToSPDX3ConformsToModel(t *testing.T) {
+	var hashes []cyclonedx.Hash
+	for _, alg := range []cyclonedx.HashAlgorithm{
+		cyclonedx.HashAlgoMD5, cyclonedx.HashAlgoSHA1, cyclonedx.HashAlgoSHA256, cyclonedx.HashAlgoSHA384,
+		cyclonedx.HashAlgoSHA512, cyclonedx.HashAlgoSHA3_256, cyclonedx.HashAlgoSHA3_384, cyclonedx.HashAlgoSHA3_512,
+		cyclonedx.HashAlgoBlake2b_256, cyclonedx.HashAlgoBlake2b_384, cyclonedx.HashAlgoBlake2b_512, cyclonedx.HashAlgoBlake3,
+	} {
+		hashes = append(hashes, cyclonedx.Hash{Algorithm: alg, Value: "ab"})
+	}
+	bom := &cyclonedx.BOM{
+		SerialNumber: "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+		Metadata: &cyclonedx.Metadata{
+			Timestamp: "2025-01-01T00:00:00Z",
+			Component: &cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, BOMRef: "app@1.0.0", Name: "acme.org/app", Version: "1.0.0", Supplier: &cyclonedx.OrganizationalEntity{Name: "ACME"}},
+			Tools:     &cyclonedx.ToolsChoice{Components: &[]cyclonedx.Component{{Name: "syft", Version: "1.30.0"}}},
+		},
+		Components: &[]cyclonedx.Component{{
+			Type: cyclonedx.ComponentTypeContainer, BOMRef: "image", Name: "image",
+			Components: &[]cyclonedx.Component{
+				{
+					Type: cyclonedx.ComponentTypeLibrary, BOMRef: "zlib", Name: "zlib", Version: "1.3",
+					PackageURL: "pkg:apk/alpine/zlib@1.3", CPE: "cpe:2.3:a:zlib:zlib:1.3:*:*:*:*:*:*:*",
+					Licenses: &cyclonedx.Licenses{{Expression: "Zlib"}}, Hashes: &hashes,
+				},
+				{Type: cyclonedx.ComponentTypeOS, BOMRef: "alpine", Name: "alpine", Version: "3.20"},
+			},
+		}},
+		Dependencies: &[]cyclonedx.Dependency{
+			{Ref: "app@1.0.0", Dependencies: &[]string{"image"}},
+			{Ref: "zlib", Dependencies: &[]string{"alpine"}},
+		},
+	}
+
+	doc, err := CycloneDXToSPDX3(bom)
+	if err != nil {
+		t.Fatalf("CycloneDXToSPDX3: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteSPDX3(doc, &buf); err != nil {
+		t.Fatalf("WriteSPDX3: %v", err)
+	}
+	var jsonld struct {
+		Context string           `json:"@context"`
+		Graph   []map[string]any `json:"@graph"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &jsonld); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if jsonld.Context != "https://spdx.org/rdf/3.0.1/spdx-context.jsonld" {
+		t.Errorf("@context = %q", jsonld.Context)
+	}
+
+	types := make(map[string]string) // spdxId -> type
+	creationInfos := make(map[string]map[string]any)
+	for _, obj := range jsonld.Graph {
+		validateSPDX3Object(t, obj)
+		if obj["type"] == "CreationInfo" {
+			creationInfos[obj["@id"].(string)] = obj
+			continue
+		}
+		id, _ := obj["spdxId"].(string)
+		if _, dup := types[id]; dup {
+			t.Errorf("duplicate spdxId %q", id)
+		}
+		types[id] = obj["type"].(string)
+	}
+
+	// Every element refers to a creationInfo of the graph, which refers to existing agents and tools
+	if len(creationInfos) != 1 {
+		t.Fatalf("creationInfo nodes = %d, want 1", len(creationInfos))
+	}
+	for _, obj := range jsonld.Graph {
+		if ref, ok := obj["creationInfo"].(string); ok && creationInfos[ref] == nil {
+			t.Errorf("%v: creationInfo %q is not a node of the graph", obj["spdxId"], ref)
+		}
+	}
+	for id, info := range creationInfos {
+		if !strings.HasPrefix(id, "_:") {
+			t.Errorf("creationInfo @id %q is not a blank node", id)
+		}
+		if v, _ := info["specVersion"].(string); v != SPDX3SpecVersion || !spdx3SpecVersion.MatchString(v) {
+			t.Errorf("specVersion = %q, want %s", v, SPDX3SpecVersion)
+		}
+		if created, _ := info["created"].(string); !spdx3DateTime.MatchString(created) {
+			t.Errorf("created = %q is not an SPDX 3 DateTime", created)
+		}
+		createdBy, _ := info["createdBy"].([]any)
+		if len(createdBy) == 0 {
+			t.Errorf("createdBy is empty")
+		}
+		for _, agent := range createdBy {
+			if typ := types[agent.(string)]; typ != "SoftwareAgent" && typ != "Organization" {
+				t.Errorf("createdBy %q is a %q, want an Agent", agent, typ)
+			}
+		}
+		createdUsing, _ := info["createdUsing"].([]any)
+		for _, tool := range createdUsing {
+			if typ := types[tool.(string)]; typ != "Tool" {
+				t.Errorf("createdUsing %q is a %q, want a Tool", tool, typ)
+			}
+		}
+	}
+
+	// Element references resolve within the graph
+	hashesSeen := make(map[string]bool)
+	for _, obj := range jsonld.Graph {
+		var refs []any
+		for _, key := range []string{"from", "suppliedBy"} {
+			if ref, ok := obj[key]; ok {
+				refs = append(refs, ref)
+			}
+		}
+		for _, key := range []string{"to", "element", "rootElement"} {
+			list, _ := obj[key].([]any)
+			refs = append(refs, list...)
+		}
+		for _, ref := range refs {
+			if _, ok := types[ref.(string)]; !ok {
+				t.Errorf("%v: reference %q does not resolve to an element", obj["spdxId"], ref)
+			}
+		}
+		if ref, ok := obj["suppliedBy"].(string); ok && types[ref] != "Organization" {
+			t.Errorf("%v: suppliedBy %q is a %q, want an Organization", obj["spdxId"], ref, types[ref])
+		}
+		verified, _ := obj["verifiedUsing"].([]any)
+		for _, h := range verified {
+			hashesSeen[h.(map[string]any)["algorithm"].(string)] = true
+		}
+	}
+	for _, alg := range []string{"md5", "sha1", "sha256", "sha384", "sha512", "sha3_256", "sha3_384", "sha3_512", "blake2b256", "blake2b384", "blake2b512", "blake3"} {
+		if !hashesSeen[alg] {
+			t.Errorf("hash algorithm %s missing from verifiedUsing", alg)
+		}
+	}
+}
+
+func TestSPDX3HashCoversChecksumAlgorithms(t *testing.T) {
+	for _, alg := range []common.ChecksumAlgorithm{
+		common.SHA224, common.SHA1, common.SHA256, common.SHA384, common.SHA512, common.MD2, common.MD4,
+		common.MD5, common.MD6, common.SHA3_256, common.SHA3_384, common.SHA3_512, common.BLAKE2b_256,
+		common.BLAKE2b_384, common.BLAKE2b_512, common.BLAKE3, common.ADLER32,
+	} {
+		hash := spdx3Hash(common.Checksum{Algorithm: alg, Value: "ab"})
+		if hash.Algorithm == "other" || !slices.Contains(spdx3ModelHashAlgorithms, hash.Algorithm) {
+			t.Errorf("%s maps to %q, want an SPDX 3.0.1 hash algorithm", alg, hash.Algorithm)
+		}
+	}
+
+	hash := spdx3Hash(common.Checksum{Algorithm: "SHA3-224", Value: "ab"})
+	if hash.Algorithm != "other" || hash.Comment != "SHA3-224" {
+		t.Errorf("unknown algorithm maps to %+v, want other with the name as comment", hash)
+	}
+}
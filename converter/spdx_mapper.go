@@ -0,0 +1,340 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/google/uuid"
+	spdxjson "github.com/spdx/tools-golang/json"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+	"github.com/spdx/tools-golang/tagvalue"
+	spdxyaml "github.com/spdx/tools-golang/yaml"
+)
+
+// spdxNamespaceBase prefixes the document namespace of generated SPDX documents.
+const spdxNamespaceBase = "https://github.com/olisonsturm/ocm-sbom/spdx"
+
+const spdxNoAssertion = "NOASSERTION"
+
+// invalidSPDXIDChars matches characters that are not allowed in SPDX element identifiers.
+var invalidSPDXIDChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// CycloneDXToSPDX23 maps a merged CycloneDX BOM to an SPDX 2.3 document. The document DESCRIBES
+// the metadata component, nested components become CONTAINS relationships and the dependency
+// graph becomes DEPENDS_ON relationships, so the OCM hierarchy survives the conversion.
+func CycloneDXToSPDX23(bom *cyclonedx.BOM) (*v2_3.Document, error) {
+	if bom == nil {
+		return nil, fmt.Errorf("cannot map SBOM: input BOM is nil")
+	}
+
+	m := &spdxMapper{ids: make(map[string]common.ElementID), used: make(map[common.ElementID]bool)}
+	doc := &v2_3.Document{
+		SPDXVersion:    v2_3.Version,
+		DataLicense:    v2_3.DataLicense,
+		SPDXIdentifier: "DOCUMENT",
+		CreationInfo:   spdxCreationInfo(bom),
+	}
+
+	var root *cyclonedx.Component
+	if bom.Metadata != nil {
+		root = bom.Metadata.Component
+	}
+	doc.DocumentName = ToolName
+	if root != nil {
+		doc.DocumentName = fmt.Sprintf("%s@%s", root.Name, root.Version)
+	}
+	serial := strings.TrimPrefix(bom.SerialNumber, "urn:uuid:")
+	if serial == "" {
+		serial = uuid.New().String()
+	}
+	doc.DocumentNamespace = fmt.Sprintf("%s/%s-%s", spdxNamespaceBase, invalidSPDXIDChars.ReplaceAllString(doc.DocumentName, "-"), serial)
+
+	// Packages and CONTAINS relationships follow the component tree
+	if root != nil {
+		rootID := m.addPackage(root)
+		m.relate(common.ElementID("DOCUMENT"), rootID, common.TypeRelationshipDescribe)
+		if root.Components != nil {
+			m.addComponents(*root.Components, rootID)
+		}
+		if bom.Components != nil {
+			m.addComponents(*bom.Components, rootID)
+		}
+	} else if bom.Components != nil {
+		for _, id := range m.addComponents(*bom.Components, "") {
+			m.relate(common.ElementID("DOCUMENT"), id, common.TypeRelationshipDescribe)
+		}
+	}
+
+	// DEPENDS_ON relationships follow the dependency graph
+	if bom.Dependencies != nil {
+		for _, dep := range *bom.Dependencies {
+			from, ok := m.ids[dep.Ref]
+			if !ok || dep.Dependencies == nil {
+				continue
+			}
+			for _, ref := range *dep.Dependencies {
+				if to, ok := m.ids[ref]; ok && to != from {
+					m.relate(from, to, common.TypeRelationshipDependsOn)
+				}
+			}
+		}
+	}
+
+	doc.Packages = m.packages
+	doc.Relationships = m.relationships
+	return doc, nil
+}
+
+// WriteSPDX23 writes an SPDX 2.3 document as JSON, YAML or tag-value.
+func WriteSPDX23(doc *v2_3.Document, w io.Writer, format SBOMFormat) error {
+	switch format {
+	case FormatSPDXJSON:
+		return spdxjson.Write(doc, w, spdxjson.Indent("  "))
+	case FormatSPDXYAML:
+		return spdxyaml.Write(doc, w)
+	case FormatSPDXTagValue:
+		return tagvalue.Write(doc, w)
+	default:
+		return fmt.Errorf("unsupported SPDX 2.3 format: %s", format)
+	}
+}
+
+// spdxMapper collects the packages and relationships of an SPDX document.
+type spdxMapper struct {
+	ids           map[string]common.ElementID // bom-ref -> SPDX identifier
+	used          map[common.ElementID]bool
+	packages      []*v2_3.Package
+	relationships []*v2_3.Relationship
+	seen          map[string]bool
+}
+
+// addComponents adds the components (recursively) and relates them to their parent.
+func (m *spdxMapper) addComponents(components []cyclonedx.Component, parent common.ElementID) []common.ElementID {
+	ids := make([]common.ElementID, 0, len(components))
+	for i := range components {
+		comp := &components[i]
+		id := m.addPackage(comp)
+		ids = append(ids, id)
+		if parent != "" {
+			m.relate(parent, id, common.TypeRelationshipContains)
+		}
+		if comp.Components != nil {
+			m.addComponents(*comp.Components, id)
+		}
+	}
+	return ids
+}
+
+// addPackage maps a component to an SPDX package and returns its identifier.
+func (m *spdxMapper) addPackage(comp *cyclonedx.Component) common.ElementID {
+	if id, ok := m.ids[comp.BOMRef]; ok && comp.BOMRef != "" {
+		return id
+	}
+	id := m.elementID(comp)
+
+	pkg := &v2_3.Package{
+		PackageName:             comp.Name,
+		PackageSPDXIdentifier:   id,
+		PackageVersion:          comp.Version,
+		PackageDownloadLocation: spdxNoAssertion,
+		PackageLicenseConcluded: spdxNoAssertion,
+		PackageLicenseDeclared:  spdxLicenseExpression(comp.Licenses),
+		PackageCopyrightText:    spdxNoAssertion,
+		PackageDescription:      comp.Description,
+		PrimaryPackagePurpose:   spdxPurpose(comp.Type),
+	}
+	if comp.Group != "" {
+		pkg.PackageName = comp.Group + "/" + comp.Name
+	}
+	if comp.Supplier != nil && comp.Supplier.Name != "" {
+		pkg.PackageSupplier = &common.Supplier{Supplier: comp.Supplier.Name, SupplierType: "Organization"}
+	}
+	if comp.Hashes != nil {
+		for _, hash := range *comp.Hashes {
+			if alg, ok := spdxChecksumAlgorithm(hash.Algorithm); ok {
+				pkg.PackageChecksums = append(pkg.PackageChecksums, common.Checksum{Algorithm: alg, Value: hash.Value})
+			}
+		}
+	}
+	if comp.PackageURL != "" {
+		pkg.PackageExternalReferences = append(pkg.PackageExternalReferences, &v2_3.PackageExternalReference{
+			Category: common.CategoryPackageManager,
+			RefType:  common.TypePackageManagerPURL,
+			Locator:  comp.PackageURL,
+		})
+	}
+	if comp.CPE != "" {
+		pkg.PackageExternalReferences = append(pkg.PackageExternalReferences, &v2_3.PackageExternalReference{
+			Category: common.CategorySecurity,
+			RefType:  common.TypeSecurityCPE23Type,
+			Locator:  comp.CPE,
+		})
+	}
+	if comp.BOMRef != "" {
+		pkg.PackageComment = "bom-ref: " + comp.BOMRef
+	}
+
+	m.packages = append(m.packages, pkg)
+	return id
+}
+
+// elementID derives a unique SPDX identifier from the bom-ref (or name and version) of a component.
+func (m *spdxMapper) elementID(comp *cyclonedx.Component) common.ElementID {
+	base := comp.BOMRef
+	if base == "" {
+		base = componentBOMRefNamespace(comp)
+	}
+	sanitized := strings.Trim(invalidSPDXIDChars.ReplaceAllString(base, "-"), "-")
+	if sanitized == "" {
+		sanitized = "Package"
+	}
+
+	id := common.ElementID(sanitized)
+	for n := 2; m.used[id]; n++ {
+		id = common.ElementID(fmt.Sprintf("%s-%d", sanitized, n))
+	}
+	m.used[id] = true
+	if comp.BOMRef != "" {
+		m.ids[comp.BOMRef] = id
+	}
+	return id
+}
+
+// relate adds a relationship once.
+func (m *spdxMapper) relate(from, to common.ElementID, relationship string) {
+	if m.seen == nil {
+		m.seen = make(map[string]bool)
+	}
+	key := string(from) + "\x00" + relationship + "\x00" + string(to)
+	if m.seen[key] {
+		return
+	}
+	m.seen[key] = true
+	m.relationships = append(m.relationships, &v2_3.Relationship{
+		RefA:         common.MakeDocElementID("", string(from)),
+		RefB:         common.MakeDocElementID("", string(to)),
+		Relationship: relationship,
+	})
+}
+
+// spdxCreationInfo maps metadata timestamp and tools to SPDX creation info.
+func spdxCreationInfo(bom *cyclonedx.BOM) *v2_3.CreationInfo {
+	info := &v2_3.CreationInfo{Created: time.Now().UTC().Format(time.RFC3339)}
+	if bom.Metadata != nil {
+		if ts, err := time.Parse(time.RFC3339, bom.Metadata.Timestamp); err == nil {
+			info.Created = ts.UTC().Format(time.RFC3339)
+		}
+		if bom.Metadata.Tools != nil && bom.Metadata.Tools.Components != nil {
+			for _, tool := range *bom.Metadata.Tools.Components {
+				name := tool.Name
+				if tool.Version != "" {
+					name = fmt.Sprintf("%s-%s", tool.Name, tool.Version)
+				}
+				info.Creators = append(info.Creators, common.Creator{Creator: name, CreatorType: "Tool"})
+			}
+		}
+	}
+	if len(info.Creators) == 0 {
+		info.Creators = []common.Creator{{Creator: ToolName, CreatorType: "Tool"}}
+	}
+	return info
+}
+
+// spdxLicenseExpression combines the licenses of a component into one SPDX license expression.
+func spdxLicenseExpression(licenses *cyclonedx.Licenses) string {
+	if licenses == nil || len(*licenses) == 0 {
+		return spdxNoAssertion
+	}
+	var parts []string
+	for _, choice := range *licenses {
+		switch {
+		case choice.Expression != "":
+			parts = append(parts, choice.Expression)
+		case choice.License != nil && choice.License.ID != "":
+			parts = append(parts, choice.License.ID)
+		case choice.License != nil && choice.License.Name != "":
+			parts = append(parts, "LicenseRef-"+strings.Trim(invalidSPDXIDChars.ReplaceAllString(choice.License.Name, "-"), "-"))
+		}
+	}
+	if len(parts) == 0 {
+		return spdxNoAssertion
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	for i, part := range parts {
+		if strings.Contains(part, " ") {
+			parts[i] = "(" + part + ")"
+		}
+	}
+	return strings.Join(parts, " AND ")
+}
+
+// spdxPurpose maps a CycloneDX component type to an SPDX primary package purpose.
+func spdxPurpose(t cyclonedx.ComponentType) string {
+	switch t {
+	case cyclonedx.ComponentTypeApplication:
+		return "APPLICATION"
+	case cyclonedx.ComponentTypeContainer:
+		return "CONTAINER"
+	case cyclonedx.ComponentTypeDevice:
+		return "DEVICE"
+	case cyclonedx.ComponentTypeFile:
+		return "FILE"
+	case cyclonedx.ComponentTypeFirmware:
+		return "FIRMWARE"
+	case cyclonedx.ComponentTypeFramework:
+		return "FRAMEWORK"
+	case cyclonedx.ComponentTypeLibrary:
+		return "LIBRARY"
+	case cyclonedx.ComponentTypeOS:
+		return "OPERATING-SYSTEM"
+	case "":
+		return ""
+	default:
+		return "OTHER"
+	}
+}
+
+// spdxChecksumAlgorithm maps CycloneDX hash algorithms to SPDX checksum algorithms.
+func spdxChecksumAlgorithm(alg cyclonedx.HashAlgorithm) (common.ChecksumAlgorithm, bool) {
+	switch alg {
+	case cyclonedx.HashAlgoMD5:
+		return common.MD5, true
+	case cyclonedx.HashAlgoSHA1:
+		return common.SHA1, true
+	case cyclonedx.HashAlgoSHA256:
+		return common.SHA256, true
+	case cyclonedx.HashAlgoSHA384:
+		return common.SHA384, true
+	case cyclonedx.HashAlgoSHA512:
+		return common.SHA512, true
+	case cyclonedx.HashAlgoSHA3_256:
+		return common.SHA3_256, true
+	case cyclonedx.HashAlgoSHA3_384:
+		return common.SHA3_384, true
+	case cyclonedx.HashAlgoSHA3_512:
+		return common.SHA3_512, true
+	case cyclonedx.HashAlgoBlake2b_256:
+		return common.BLAKE2b_256, true
+	case cyclonedx.HashAlgoBlake2b_384:
+		return common.BLAKE2b_384, true
+	case cyclonedx.HashAlgoBlake2b_512:
+		return common.BLAKE2b_512, true
+	case cyclonedx.HashAlgoBlake3:
+		return common.BLAKE3, true
+	default:
+		return "", false
+	}
+}
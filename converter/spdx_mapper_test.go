@@ -0,0 +1,123 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	spdxjson "github.com/spdx/tools-golang/json"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+	"github.com/spdx/tools-golang/tagvalue"
+)
+
+// spdxRelationships returns the relationships of a document as "A RELATIONSHIP B".
+func spdxRelationships(doc *v2_3.Document) []string {
+	var relationships []string
+	for _, r := range doc.Relationships {
+		relationships = append(relationships, string(r.RefA.ElementRefID)+" "+r.Relationship+" "+string(r.RefB.ElementRefID))
+	}
+	return relationships
+}
+
+// spdxHierarchyBOM returns a component version with a nested component version, two images
+// sharing a package and a dependency graph.
+func spdxHierarchyBOM() *cyclonedx.BOM {
+	zlib := cyclonedx.Component{Type: cyclonedx.ComponentTypeLibrary, Name: "zlib", Version: "1.3", PackageURL: "pkg:apk/alpine/zlib@1.3", BOMRef: "zlib"}
+	bom := cyclonedx.NewBOM()
+	bom.SerialNumber = "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79"
+	bom.Metadata = &cyclonedx.Metadata{
+		Timestamp: "2025-03-01T12:00:00Z",
+		Component: &cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/app", Version: "1.0.0", BOMRef: "app"},
+	}
+	bom.Components = &[]cyclonedx.Component{
+		{Type: cyclonedx.ComponentTypeContainer, Name: "frontend", BOMRef: "frontend", Components: &[]cyclonedx.Component{zlib}},
+		{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/lib", Version: "2.0.0", BOMRef: "lib", Components: &[]cyclonedx.Component{
+			// The same package found in another image keeps one element
+			{Type: cyclonedx.ComponentTypeContainer, Name: "backend", BOMRef: "backend", Components: &[]cyclonedx.Component{zlib}},
+		}},
+		// Bom-refs that differ only in characters SPDX identifiers cannot hold
+		{Type: cyclonedx.ComponentTypeLibrary, Name: "musl", Version: "1.2.5", BOMRef: "pkg/musl"},
+		{Type: cyclonedx.ComponentTypeLibrary, Name: "musl", Version: "1.2.5", BOMRef: "pkg:musl"},
+	}
+	bom.Dependencies = &[]cyclonedx.Dependency{
+		{Ref: "app", Dependencies: &[]string{"frontend", "lib"}},
+		{Ref: "frontend", Dependencies: &[]string{"zlib", "pkg/musl"}},
+		{Ref: "zlib", Dependencies: &[]string{"zlib"}},
+	}
+	return bom
+}
+
+func TestCycloneDXToSPDX23KeepsOCMHierarchy(t *testing.T) {
+	doc, err := CycloneDXToSPDX23(spdxHierarchyBOM())
+	if err != nil {
+		t.Fatalf("CycloneDXToSPDX23: %v", err)
+	}
+
+	var ids []common.ElementID
+	for _, pkg := range doc.Packages {
+		ids = append(ids, pkg.PackageSPDXIdentifier)
+	}
+	if want := []common.ElementID{"app", "frontend", "zlib", "lib", "backend", "pkg-musl", "pkg-musl-2"}; !slices.Equal(ids, want) {
+		t.Errorf("element IDs = %v, want %v", ids, want)
+	}
+
+	want := []string{
+		"DOCUMENT DESCRIBES app",
+		"app CONTAINS frontend",
+		"frontend CONTAINS zlib",
+		"app CONTAINS lib",
+		"lib CONTAINS backend",
+		"backend CONTAINS zlib",
+		"app CONTAINS pkg-musl",
+		"app CONTAINS pkg-musl-2",
+		"app DEPENDS_ON frontend",
+		"app DEPENDS_ON lib",
+		"frontend DEPENDS_ON zlib",
+		"frontend DEPENDS_ON pkg-musl",
+	}
+	if got := spdxRelationships(doc); !slices.Equal(got, want) {
+		t.Errorf("relationships =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestWriteSPDX23RoundTrips(t *testing.T) {
+	doc, err := CycloneDXToSPDX23(spdxHierarchyBOM())
+	if err != nil {
+		t.Fatalf("CycloneDXToSPDX23: %v", err)
+	}
+	for _, format := range []SBOMFormat{FormatSPDXJSON, FormatSPDXTagValue} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteSPDX23(doc, &buf, format); err != nil {
+				t.Fatalf("WriteSPDX23: %v", err)
+			}
+			read := &v2_3.Document{}
+			if format == FormatSPDXJSON {
+				err = spdxjson.ReadInto(&buf, read)
+			} else {
+				err = tagvalue.ReadInto(&buf, read)
+			}
+			if err != nil {
+				t.Fatalf("reading the document back: %v", err)
+			}
+
+			if read.SPDXVersion != v2_3.Version || read.DocumentNamespace != doc.DocumentNamespace || len(read.Packages) != len(doc.Packages) {
+				t.Errorf("document read back = %s %s with %d packages, want %s %s with %d", read.SPDXVersion, read.DocumentNamespace, len(read.Packages), doc.SPDXVersion, doc.DocumentNamespace, len(doc.Packages))
+			}
+			got, want := spdxRelationships(read), spdxRelationships(doc)
+			slices.Sort(got)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("relationships read back = %q, want %q", got, want)
+			}
+		})
+	}
+}
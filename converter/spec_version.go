@@ -0,0 +1,121 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+// DefaultSpecVersion is the CycloneDX version SBOMs are merged in and written by default.
+const DefaultSpecVersion = cyclonedx.SpecVersion1_6
+
+// SupportedSpecVersions lists the CycloneDX versions ocm-sbom can write.
+var SupportedSpecVersions = []cyclonedx.SpecVersion{
+	cyclonedx.SpecVersion1_4,
+	cyclonedx.SpecVersion1_5,
+	cyclonedx.SpecVersion1_6,
+}
+
+// ParseSpecVersion parses a CycloneDX version like "1.5".
+func ParseSpecVersion(version string) (cyclonedx.SpecVersion, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	for _, supported := range SupportedSpecVersions {
+		if supported.String() == version {
+			return supported, nil
+		}
+	}
+	names := make([]string, len(SupportedSpecVersions))
+	for i, supported := range SupportedSpecVersions {
+		names[i] = supported.String()
+	}
+	return 0, fmt.Errorf("unsupported CycloneDX spec version %q (supported: %s)", version, strings.Join(names, ", "))
+}
+
+// specVersion returns the configured CycloneDX output version, defaulting to DefaultSpecVersion.
+func (c *CLIConverter) specVersion() cyclonedx.SpecVersion {
+	if c.SpecVersion == 0 {
+		return DefaultSpecVersion
+	}
+	return c.SpecVersion
+}
+
+// EncodeCycloneDX writes a BOM as CycloneDX JSON or XML in the given spec version. Fields the
+// version does not support are converted to an older equivalent where one exists and dropped
+// otherwise.
+func EncodeCycloneDX(bom *cyclonedx.BOM, w io.Writer, format cyclonedx.BOMFileFormat, version cyclonedx.SpecVersion) error {
+	if version < bom.SpecVersion {
+		// Downgrade a copy so the BOM can still be rendered into other formats
+		clone, err := cloneBOM(bom)
+		if err != nil {
+			return err
+		}
+		bom = clone
+		downgradeBOM(bom, version)
+	}
+	encoder := cyclonedx.NewBOMEncoder(w, format)
+	encoder.SetPretty(true)
+	// EncodeVersion removes what is left unsupported by the target version
+	return encoder.EncodeVersion(bom, version)
+}
+
+// cloneBOM returns a deep copy of a BOM.
+func cloneBOM(bom *cyclonedx.BOM) (*cyclonedx.BOM, error) {
+	raw, err := json.Marshal(bom)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy BOM: %w", err)
+	}
+	var clone cyclonedx.BOM
+	if err := json.Unmarshal(raw, &clone); err != nil {
+		return nil, fmt.Errorf("failed to copy BOM: %w", err)
+	}
+	return &clone, nil
+}
+
+// downgradeBOM moves fields introduced after the target version to their older equivalents
+// before the encoder strips them.
+func downgradeBOM(bom *cyclonedx.BOM, version cyclonedx.SpecVersion) {
+	if version >= cyclonedx.SpecVersion1_6 {
+		return
+	}
+	if bom.Metadata != nil {
+		// metadata.manufacturer replaced metadata.manufacture in 1.6
+		if bom.Metadata.Manufacturer != nil && bom.Metadata.Manufacture == nil {
+			bom.Metadata.Manufacture = bom.Metadata.Manufacturer
+		}
+		if bom.Metadata.Component != nil {
+			downgradeComponent(bom.Metadata.Component)
+		}
+	}
+	if bom.Components != nil {
+		for i := range *bom.Components {
+			downgradeComponent(&(*bom.Components)[i])
+		}
+	}
+}
+
+// downgradeComponent replaces the 1.6 component authors with the legacy author string.
+func downgradeComponent(comp *cyclonedx.Component) {
+	if comp.Authors != nil && comp.Author == "" {
+		names := make([]string, 0, len(*comp.Authors))
+		for _, author := range *comp.Authors {
+			if author.Name != "" {
+				names = append(names, author.Name)
+			}
+		}
+		comp.Author = strings.Join(names, ", ")
+	}
+	if comp.Components != nil {
+		for i := range *comp.Components {
+			downgradeComponent(&(*comp.Components)[i])
+		}
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+func TestEncodeCycloneDXDowngrades(t *testing.T) {
+	acme := &cyclonedx.OrganizationalEntity{Name: "ACME"}
+	bom := cyclonedx.NewBOM()
+	bom.Metadata = &cyclonedx.Metadata{
+		Manufacturer: acme,
+		Component:    &cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "acme.org/app", Version: "1.0.0", BOMRef: "app"},
+	}
+	bom.Components = &[]cyclonedx.Component{{
+		Type: cyclonedx.ComponentTypeContainer, Name: "image", BOMRef: "image",
+		Components: &[]cyclonedx.Component{{
+			Type: cyclonedx.ComponentTypeLibrary, Name: "zlib", Version: "1.3", BOMRef: "zlib",
+			Authors: &[]cyclonedx.OrganizationalContact{{Name: "Jean-loup Gailly"}, {Name: "Mark Adler"}},
+		}},
+	}}
+	bom.Declarations = &cyclonedx.Declarations{Assessors: &[]cyclonedx.Assessor{{BOMRef: "assessor", Organization: acme}}}
+	before, err := json.Marshal(bom)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, version := range []cyclonedx.SpecVersion{cyclonedx.SpecVersion1_4, cyclonedx.SpecVersion1_5} {
+		t.Run(version.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := EncodeCycloneDX(bom, &buf, cyclonedx.BOMFileFormatJSON, version); err != nil {
+				t.Fatalf("EncodeCycloneDX: %v", err)
+			}
+			var out struct {
+				SpecVersion string `json:"specVersion"`
+				Metadata    struct {
+					Manufacture  *cyclonedx.OrganizationalEntity `json:"manufacture"`
+					Manufacturer *cyclonedx.OrganizationalEntity `json:"manufacturer"`
+				} `json:"metadata"`
+				Components []struct {
+					Components []struct {
+						Author  string          `json:"author"`
+						Authors json.RawMessage `json:"authors"`
+					} `json:"components"`
+				} `json:"components"`
+				Declarations json.RawMessage `json:"declarations"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+				t.Fatalf("output is not JSON: %v", err)
+			}
+
+			if out.SpecVersion != version.String() {
+				t.Errorf("specVersion = %s, want %s", out.SpecVersion, version)
+			}
+			if out.Metadata.Manufacture == nil || out.Metadata.Manufacture.Name != "ACME" || out.Metadata.Manufacturer != nil {
+				t.Errorf("metadata manufacture = %+v, manufacturer = %+v, want ACME as manufacture", out.Metadata.Manufacture, out.Metadata.Manufacturer)
+			}
+			if len(out.Components) != 1 || len(out.Components[0].Components) != 1 {
+				t.Fatalf("components = %s", buf.Bytes())
+			}
+			if zlib := out.Components[0].Components[0]; zlib.Author != "Jean-loup Gailly, Mark Adler" || zlib.Authors != nil {
+				t.Errorf("nested author = %q, authors = %s, want the authors joined", zlib.Author, zlib.Authors)
+			}
+			if out.Declarations != nil {
+				t.Errorf("declarations = %s, want them dropped", out.Declarations)
+			}
+		})
+	}
+
+	after, err := json.Marshal(bom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Errorf("EncodeCycloneDX modified the BOM:\n%s\n---\n%s", before, after)
+	}
+}
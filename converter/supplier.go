@@ -0,0 +1,203 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// SupplierConfig overrides the suppliers derived from OCM providers. It is read from YAML or JSON:
+//
+//	providers:
+//	  acme.org:
+//	    name: ACME Corporation
+//	    url: [https://acme.org]
+//	    contacts:
+//	      - name: Security Team
+//	        email: security@acme.org
+//	components:
+//	  github.com/acme/legacy/*:
+//	    name: ACME Legacy Division
+type SupplierConfig struct {
+	// Providers maps OCM provider names to the supplier reported for their components.
+	Providers map[string]SupplierOverride `json:"providers,omitempty"`
+	// Components maps component name patterns (path.Match syntax) to the supplier reported for
+	// matching components. They take precedence over Providers; the longest matching pattern wins.
+	Components map[string]SupplierOverride `json:"components,omitempty"`
+}
+
+// SupplierOverride replaces the fields of a supplier that are set.
+type SupplierOverride struct {
+	Name     string            `json:"name,omitempty"`
+	URL      []string          `json:"url,omitempty"`
+	Contacts []SupplierContact `json:"contacts,omitempty"`
+}
+
+// SupplierContact is a contact of a supplier.
+type SupplierContact struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+}
+
+// LoadSupplierConfig reads a SupplierConfig from a YAML or JSON file.
+func LoadSupplierConfig(configPath string) (*SupplierConfig, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read supplier config %s: %w", configPath, err)
+	}
+	var config SupplierConfig
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse supplier config %s: %w", configPath, err)
+	}
+	for pattern := range config.Components {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid component pattern %q in supplier config %s: %w", pattern, configPath, err)
+		}
+	}
+	return &config, nil
+}
+
+// override returns the override for a component, if any.
+func (sc *SupplierConfig) override(componentName, providerName string) (SupplierOverride, bool) {
+	if sc == nil {
+		return SupplierOverride{}, false
+	}
+	patterns := make([]string, 0, len(sc.Components))
+	for pattern := range sc.Components {
+		patterns = append(patterns, pattern)
+	}
+	// Most specific first; sort by name for a stable choice between equally long patterns
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, componentName); ok {
+			return sc.Components[pattern], true
+		}
+	}
+	o, ok := sc.Providers[providerName]
+	return o, ok
+}
+
+// ProviderSupplier maps the provider of a component version to a CycloneDX organizational
+// entity. Provider labels are recognized by the last segment of their name: "url", "homepage"
+// and "website" give the URLs, "contact", "contacts", "email" and "phone" the contacts. Contact
+// values are strings or objects with name, email and phone, or lists of them. Overrides from
+// config replace the derived fields. It returns nil if no supplier name is known.
+func ProviderSupplier(descriptor *runtime.Descriptor, config *SupplierConfig) *cyclonedx.OrganizationalEntity {
+	provider := descriptor.Component.Provider
+	entity := &cyclonedx.OrganizationalEntity{Name: provider.Name}
+
+	var urls []string
+	var contacts []cyclonedx.OrganizationalContact
+	for _, label := range provider.Labels {
+		key := strings.ToLower(label.Name)
+		if i := strings.LastIndex(key, "/"); i >= 0 {
+			key = key[i+1:]
+		}
+		switch key {
+		case "url", "homepage", "website":
+			values, err := labelStrings(label.Value)
+			if err != nil {
+				log.Printf("Warning: provider label %s of %s is not a URL: %v", label.Name, descriptor.Component.Name, err)
+				continue
+			}
+			urls = append(urls, values...)
+		case "contact", "contacts", "email", "phone":
+			found, err := labelContacts(key, label.Value)
+			if err != nil {
+				log.Printf("Warning: provider label %s of %s is not a contact: %v", label.Name, descriptor.Component.Name, err)
+				continue
+			}
+			contacts = append(contacts, found...)
+		}
+	}
+
+	if o, ok := config.override(descriptor.Component.Name, provider.Name); ok {
+		if o.Name != "" {
+			entity.Name = o.Name
+		}
+		if len(o.URL) > 0 {
+			urls = o.URL
+		}
+		if len(o.Contacts) > 0 {
+			contacts = nil
+			for _, contact := range o.Contacts {
+				contacts = append(contacts, cyclonedx.OrganizationalContact{Name: contact.Name, Email: contact.Email, Phone: contact.Phone})
+			}
+		}
+	}
+
+	if entity.Name == "" {
+		return nil
+	}
+	if len(urls) > 0 {
+		entity.URL = &urls
+	}
+	if len(contacts) > 0 {
+		entity.Contact = &contacts
+	}
+	return entity
+}
+
+// labelStrings decodes a label value that is a string or a list of strings.
+func labelStrings(raw json.RawMessage) ([]string, error) {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return []string{single}, nil
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// labelContacts decodes a contact label value. Plain strings are taken as phone numbers for
+// "phone" labels, as email addresses if they contain an @ and as names otherwise.
+func labelContacts(key string, raw json.RawMessage) ([]cyclonedx.OrganizationalContact, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		items = []json.RawMessage{raw}
+	}
+
+	var contacts []cyclonedx.OrganizationalContact
+	for _, item := range items {
+		var s string
+		if err := json.Unmarshal(item, &s); err == nil {
+			switch {
+			case key == "phone":
+				contacts = append(contacts, cyclonedx.OrganizationalContact{Phone: s})
+			case strings.Contains(s, "@"):
+				contacts = append(contacts, cyclonedx.OrganizationalContact{Email: s})
+			default:
+				contacts = append(contacts, cyclonedx.OrganizationalContact{Name: s})
+			}
+			continue
+		}
+		var contact SupplierContact
+		if err := json.Unmarshal(item, &contact); err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, cyclonedx.OrganizationalContact{Name: contact.Name, Email: contact.Email, Phone: contact.Phone})
+	}
+	return contacts, nil
+}
@@ -0,0 +1,130 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
+)
+
+// providerDescriptor returns a descriptor of name whose provider has the given labels.
+func providerDescriptor(name, provider string, labels ...runtime.Label) *runtime.Descriptor {
+	d := testDescriptor(name)
+	d.Component.Provider.Name = provider
+	d.Component.Provider.Labels = labels
+	return d
+}
+
+func TestProviderSupplierParsesLabels(t *testing.T) {
+	descriptor := providerDescriptor("acme.org/app", "acme.org",
+		runtime.Label{Name: "acme.org/homepage", Value: json.RawMessage(`"https://acme.org"`)},
+		runtime.Label{Name: "url", Value: json.RawMessage(`["https://docs.acme.org", "https://status.acme.org"]`)},
+		runtime.Label{Name: "acme.org/email", Value: json.RawMessage(`"security@acme.org"`)},
+		runtime.Label{Name: "Phone", Value: json.RawMessage(`"+49 30 1234"`)},
+		runtime.Label{Name: "acme.org/contact", Value: json.RawMessage(`"Release Team"`)},
+		runtime.Label{Name: "acme.org/contacts", Value: json.RawMessage(`[{"name": "Ops", "email": "ops@acme.org", "phone": "+49 30 5678"}, "oncall@acme.org"]`)},
+		runtime.Label{Name: "acme.org/url", Value: json.RawMessage(`{"not": "a url"}`)},
+		runtime.Label{Name: "acme.org/team", Value: json.RawMessage(`"platform"`)},
+	)
+
+	got := ProviderSupplier(descriptor, nil)
+	want := &cyclonedx.OrganizationalEntity{
+		Name: "acme.org",
+		URL:  &[]string{"https://acme.org", "https://docs.acme.org", "https://status.acme.org"},
+		Contact: &[]cyclonedx.OrganizationalContact{
+			{Email: "security@acme.org"},
+			{Phone: "+49 30 1234"},
+			{Name: "Release Team"},
+			{Name: "Ops", Email: "ops@acme.org", Phone: "+49 30 5678"},
+			{Email: "oncall@acme.org"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(want)
+		t.Errorf("supplier =\n%s\nwant\n%s", gotJSON, wantJSON)
+	}
+
+	if supplier := ProviderSupplier(providerDescriptor("acme.org/app", ""), nil); supplier != nil {
+		t.Errorf("supplier = %+v, want none without a provider name", supplier)
+	}
+}
+
+func TestProviderSupplierOverrides(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "suppliers.yaml")
+	if err := os.WriteFile(configPath, []byte(`providers:
+  acme.org:
+    name: ACME Corporation
+    url: [https://acme.org]
+components:
+  github.com/acme/*:
+    name: ACME Open Source
+  github.com/acme/legacy-*:
+    name: ACME Legacy Division
+    contacts:
+      - name: Legacy Support
+        email: legacy@acme.org
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadSupplierConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadSupplierConfig: %v", err)
+	}
+	label := runtime.Label{Name: "acme.org/email", Value: json.RawMessage(`"security@acme.org"`)}
+
+	tests := []struct {
+		name      string
+		component string
+		provider  string
+		want      *cyclonedx.OrganizationalEntity
+	}{
+		{
+			name: "provider", component: "acme.org/app", provider: "acme.org",
+			want: &cyclonedx.OrganizationalEntity{Name: "ACME Corporation", URL: &[]string{"https://acme.org"}, Contact: &[]cyclonedx.OrganizationalContact{{Email: "security@acme.org"}}},
+		},
+		{
+			// A component pattern wins over the provider and keeps the fields it does not set
+			name: "component pattern", component: "github.com/acme/app", provider: "acme.org",
+			want: &cyclonedx.OrganizationalEntity{Name: "ACME Open Source", Contact: &[]cyclonedx.OrganizationalContact{{Email: "security@acme.org"}}},
+		},
+		{
+			name: "longest component pattern", component: "github.com/acme/legacy-billing", provider: "acme.org",
+			want: &cyclonedx.OrganizationalEntity{Name: "ACME Legacy Division", Contact: &[]cyclonedx.OrganizationalContact{{Name: "Legacy Support", Email: "legacy@acme.org"}}},
+		},
+		{
+			name: "no override", component: "other.org/app", provider: "other.org",
+			want: &cyclonedx.OrganizationalEntity{Name: "other.org", Contact: &[]cyclonedx.OrganizationalContact{{Email: "security@acme.org"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ProviderSupplier(providerDescriptor(tt.component, tt.provider, label), config)
+			if !reflect.DeepEqual(got, tt.want) {
+				gotJSON, _ := json.Marshal(got)
+				wantJSON, _ := json.Marshal(tt.want)
+				t.Errorf("supplier =\n%s\nwant\n%s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+func TestLoadSupplierConfigRejectsInvalidPatterns(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "suppliers.yaml")
+	if err := os.WriteFile(configPath, []byte("components:\n  \"acme.org/[\":\n    name: ACME\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSupplierConfig(configPath); err == nil {
+		t.Errorf("invalid component pattern accepted")
+	}
+}
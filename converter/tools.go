@@ -0,0 +1,215 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/anchore/clio"
+)
+
+const (
+	// ToolName is the name under which ocm-sbom lists itself in metadata.tools.
+	ToolName = "ocm-sbom"
+
+	// toolPropertyPrefix namespaces the properties describing the toolchain configuration.
+	toolPropertyPrefix = "ocm-sbom"
+
+	syftModulePath = "github.com/anchore/syft"
+)
+
+// ToolDescriber is implemented by mergers that can describe themselves as a tool component.
+type ToolDescriber interface {
+	Tool() cyclonedx.Component
+}
+
+// SyftVersion returns the version of the Syft library embedded in this binary.
+func SyftVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != syftModulePath {
+			continue
+		}
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			return dep.Replace.Version
+		}
+		return dep.Version
+	}
+	return "unknown"
+}
+
+// toolVersion returns the configured ocm-sbom version.
+func (c *CLIConverter) toolVersion() string {
+	if c.ToolVersion == "" {
+		return "dev"
+	}
+	return c.ToolVersion
+}
+
+// scanIdentification identifies the embedded Syft in the SBOMs it produces.
+func (c *CLIConverter) scanIdentification() clio.Identification {
+	return clio.Identification{
+		Name:    "syft",
+		Version: SyftVersion(),
+	}
+}
+
+// scanConfig returns the effective scan configuration for resource scans. Resource SBOMs are
+// always CycloneDX JSON, the input format of every merger; the target format is rendered from
+// the merged SBOM.
+func (c *CLIConverter) scanConfig() *ScanConfig {
+	config := DefaultScanConfig()
+	config.OutputFormats = []string{string(FormatCycloneDXJSON)}
+	return config
+}
+
+// ScanConfigProperties describes the settings of a scan configuration as properties.
+func ScanConfigProperties(config *ScanConfig) []cyclonedx.Property {
+	prop := func(key, value string) cyclonedx.Property {
+		return cyclonedx.Property{Name: toolPropertyPrefix + ":scan:" + key, Value: value}
+	}
+	from := "auto"
+	if len(config.Catalog.From) > 0 {
+		from = strings.Join(config.Catalog.From, ",")
+	}
+	platform := "default"
+	if config.Catalog.Platform != "" {
+		platform = config.Catalog.Platform
+	}
+
+	props := []cyclonedx.Property{
+		prop("output-format", strings.Join(config.OutputFormats, ",")),
+		prop("from", from),
+		prop("platform", platform),
+	}
+	if len(config.Catalog.Exclusions) > 0 {
+		props = append(props, prop("exclusions", strings.Join(config.Catalog.Exclusions, ",")))
+	}
+	if config.Catalog.Source.BasePath != "" {
+		props = append(props, prop("base-path", config.Catalog.Source.BasePath))
+	}
+	if config.Catalog.Source.Name != "" {
+		props = append(props, prop("source-name", config.Catalog.Source.Name))
+	}
+	if config.Catalog.Source.Version != "" {
+		props = append(props, prop("source-version", config.Catalog.Source.Version))
+	}
+	return props
+}
+
+// toolComponents lists the toolchain that produced the final SBOM: ocm-sbom itself, the embedded
+// Syft with its scan configuration and the merge tool with the merge strategy.
+func (c *CLIConverter) toolComponents(mergeTool string, formats []SBOMFormat) []cyclonedx.Component {
+	formatNames := make([]string, len(formats))
+	hasCycloneDX := false
+	for i, format := range formats {
+		formatNames[i] = string(format)
+		hasCycloneDX = hasCycloneDX || strings.HasPrefix(string(format), "cyclonedx")
+	}
+	ocmSbom := cyclonedx.Component{
+		Type:    cyclonedx.ComponentTypeApplication,
+		Name:    ToolName,
+		Version: c.toolVersion(),
+		Properties: &[]cyclonedx.Property{
+			{Name: toolPropertyPrefix + ":output-format", Value: strings.Join(formatNames, ",")},
+		},
+	}
+	if hasCycloneDX {
+		*ocmSbom.Properties = append(*ocmSbom.Properties, cyclonedx.Property{Name: toolPropertyPrefix + ":spec-version", Value: c.specVersion().String()})
+	}
+
+	scanProps := ScanConfigProperties(c.scanConfig())
+	syft := cyclonedx.Component{
+		Type:       cyclonedx.ComponentTypeLibrary,
+		Author:     "anchore",
+		Name:       "syft",
+		Version:    SyftVersion(),
+		PackageURL: "pkg:golang/" + syftModulePath + "@" + SyftVersion(),
+		Properties: &scanProps,
+	}
+
+	merger := cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: mergeTool}
+	if m, err := LookupMerger(mergeTool, c); err == nil {
+		if describer, ok := m.(ToolDescriber); ok {
+			merger = describer.Tool()
+		}
+	}
+	mergeProps := []cyclonedx.Property{
+		{Name: toolPropertyPrefix + ":role", Value: "merge"},
+		{Name: toolPropertyPrefix + ":merge:tool", Value: mergeTool},
+		{Name: toolPropertyPrefix + ":merge:mode", Value: string(c.mergeMode())},
+		{Name: toolPropertyPrefix + ":merge:dedup", Value: strconv.FormatBool(c.Deduplicate)},
+	}
+	if c.MergePolicy != nil {
+		mergeProps = append(mergeProps, cyclonedx.Property{Name: toolPropertyPrefix + ":merge:policy", Value: c.MergePolicy.String()})
+	}
+	merger.Properties = &mergeProps
+
+	return []cyclonedx.Component{ocmSbom, syft, merger}
+}
+
+// setToolComponents replaces the tool components of a BOM with the given toolchain, keeping
+// tools recorded by other producers.
+func setToolComponents(bom *cyclonedx.BOM, tools []cyclonedx.Component) {
+	if bom.Metadata == nil {
+		bom.Metadata = &cyclonedx.Metadata{}
+	}
+	if bom.Metadata.Tools == nil {
+		bom.Metadata.Tools = &cyclonedx.ToolsChoice{}
+	}
+
+	result := append([]cyclonedx.Component{}, tools...)
+	if bom.Metadata.Tools.Components != nil {
+		for _, existing := range *bom.Metadata.Tools.Components {
+			if !containsTool(result, existing) {
+				result = append(result, existing)
+			}
+		}
+	}
+	bom.Metadata.Tools.Components = &result
+}
+
+// containsTool reports whether a tool with the same name and version is already listed.
+func containsTool(tools []cyclonedx.Component, tool cyclonedx.Component) bool {
+	for _, t := range tools {
+		if t.Name == tool.Name && (t.Version == tool.Version || tool.Version == "") {
+			return true
+		}
+	}
+	return false
+}
+
+// Tool implements ToolDescriber.
+func (m NativeMerger) Tool() cyclonedx.Component {
+	return cyclonedx.Component{
+		Type:        cyclonedx.ComponentTypeApplication,
+		Name:        ToolName + "-native-merge",
+		Version:     m.Version,
+		Description: "Built-in CycloneDX merge of ocm-sbom",
+	}
+}
+
+// Tool implements ToolDescriber.
+func (m *CycloneDXCLIMerger) Tool() cyclonedx.Component {
+	return cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, Name: "cyclonedx-cli"}
+}
+
+// Tool implements ToolDescriber.
+func (m *ExternalCommandMerger) Tool() cyclonedx.Component {
+	return cyclonedx.Component{
+		Type:        cyclonedx.ComponentTypeApplication,
+		Name:        filepath.Base(m.Command),
+		Description: "External merger (protocol version " + ExternalMergeProtocolVersion + ")",
+	}
+}
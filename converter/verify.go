@@ -0,0 +1,393 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"ocm.software/open-component-model/bindings/go/descriptor/normalisation"
+	_ "ocm.software/open-component-model/bindings/go/descriptor/normalisation/json/v4alpha1"
+	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
+	"ocm.software/open-component-model/bindings/go/oci"
+)
+
+// ErrSignatureVerification is returned when a component version of the graph fails signature
+// verification.
+var ErrSignatureVerification = errors.New("signature verification failed")
+
+// Signature algorithms of OCM component version signatures.
+const (
+	SignatureAlgorithmRSAPKCS1v15 = "RSASSA-PKCS1-V1_5"
+	SignatureAlgorithmRSAPSS      = "RSASSA-PSS"
+	SignatureAlgorithmECDSA       = "ECDSA"
+)
+
+// pemSignatureBlock is the PEM block type of signatures stored together with their certificate
+// chain (media type application/x-pem-file).
+const pemSignatureBlock = "SIGNATURE"
+
+// VerificationKey is the key the signature with the given name must verify against.
+type VerificationKey struct {
+	Signature string
+	PublicKey crypto.PublicKey
+	// Certificate is set if the key was given as signing certificate; it must chain to the CA
+	// bundle.
+	Certificate *x509.Certificate
+}
+
+// SignatureVerifier verifies the signatures of component versions with local keys.
+type SignatureVerifier struct {
+	Keys []VerificationKey
+	// Roots are the CA certificates signing certificates must chain to.
+	Roots *x509.CertPool
+}
+
+// NewSignatureVerifier loads the keys given as "name=publickey.pem" and the CA bundle signing
+// certificates are verified with.
+func NewSignatureVerifier(specs []string, caBundle string) (*SignatureVerifier, error) {
+	v := &SignatureVerifier{}
+	if caBundle != "" {
+		roots, err := LoadCABundle(caBundle)
+		if err != nil {
+			return nil, err
+		}
+		v.Roots = roots
+	}
+	for _, spec := range specs {
+		name, path, found := strings.Cut(spec, "=")
+		name, path = strings.TrimSpace(name), strings.TrimSpace(path)
+		if !found || name == "" || path == "" {
+			return nil, fmt.Errorf("invalid signature key %q, expected <signature name>=<key file>", spec)
+		}
+		key, err := LoadVerificationKey(name, path)
+		if err != nil {
+			return nil, err
+		}
+		if key.Certificate != nil && v.Roots == nil {
+			return nil, fmt.Errorf("key file %s of signature %q is a certificate, a CA bundle is required to verify it", path, name)
+		}
+		v.Keys = append(v.Keys, key)
+	}
+	return v, nil
+}
+
+// LoadVerificationKey reads an RSA or ECDSA public key, or a certificate holding one, from a PEM
+// file.
+func LoadVerificationKey(name, path string) (VerificationKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return VerificationKey{}, fmt.Errorf("failed to read key file %s: %w", path, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return VerificationKey{}, fmt.Errorf("key file %s is not PEM encoded", path)
+	}
+
+	key := VerificationKey{Signature: name}
+	switch block.Type {
+	case "PUBLIC KEY":
+		key.PublicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key.PublicKey, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		key.Certificate, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			key.PublicKey = key.Certificate.PublicKey
+		}
+	default:
+		return VerificationKey{}, fmt.Errorf("key file %s holds a %s, expected a public key or certificate", path, block.Type)
+	}
+	if err != nil {
+		return VerificationKey{}, fmt.Errorf("failed to parse key file %s: %w", path, err)
+	}
+	switch key.PublicKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+	default:
+		return VerificationKey{}, fmt.Errorf("key file %s holds a %T, only RSA and ECDSA keys are supported", path, key.PublicKey)
+	}
+	return key, nil
+}
+
+// LoadCABundle reads the PEM encoded CA certificates of a bundle file.
+func LoadCABundle(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle %s: %w", path, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("CA bundle %s holds no PEM certificates", path)
+	}
+	return pool, nil
+}
+
+// VerificationFailure is a component version that failed verification.
+type VerificationFailure struct {
+	Component string
+	Version   string
+	// Signature is the name of the failed signature; empty for failures of the component version
+	// as a whole.
+	Signature string
+	Reason    string
+}
+
+// VerificationReport is the outcome of verifying a component graph.
+type VerificationReport struct {
+	// Verified lists the component versions that passed, as "name:version".
+	Verified []string
+	Failures []VerificationFailure
+}
+
+// String lists the failures one per line, e.g.
+// "acme.org/app:1.0.0 [release]: descriptor digest mismatch".
+func (r VerificationReport) String() string {
+	var b strings.Builder
+	for _, f := range r.Failures {
+		fmt.Fprintf(&b, "  %s:%s", f.Component, f.Version)
+		if f.Signature != "" {
+			fmt.Fprintf(&b, " [%s]", f.Signature)
+		}
+		fmt.Fprintf(&b, ": %s\n", f.Reason)
+	}
+	return b.String()
+}
+
+// VerifyDescriptor verifies every configured signature of a component version and returns the
+// failures.
+func (v *SignatureVerifier) VerifyDescriptor(descriptor *runtime.Descriptor) []VerificationFailure {
+	var failures []VerificationFailure
+	for _, key := range v.Keys {
+		var err error
+		if sig := findSignature(descriptor, key.Signature); sig == nil {
+			err = errors.New("signature not found")
+		} else {
+			err = v.verifySignature(descriptor, *sig, key)
+		}
+		if err != nil {
+			failures = append(failures, VerificationFailure{
+				Component: descriptor.Component.Name,
+				Version:   descriptor.Component.Version,
+				Signature: key.Signature,
+				Reason:    err.Error(),
+			})
+		}
+	}
+	return failures
+}
+
+// verifySignature checks that the signed digest is the digest of the descriptor and that the
+// signature over it was made with the key.
+func (v *SignatureVerifier) verifySignature(descriptor *runtime.Descriptor, sig runtime.Signature, key VerificationKey) error {
+	hash, err := signatureHash(sig.Digest.HashAlgorithm)
+	if err != nil {
+		return err
+	}
+	digest, err := DescriptorDigest(descriptor, sig.Digest.NormalisationAlgorithm, hash)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(digest, sig.Digest.Value) {
+		return fmt.Errorf("descriptor digest mismatch: signed %s, computed %s", sig.Digest.Value, digest)
+	}
+	sum, _ := hex.DecodeString(digest)
+
+	value, chain, err := decodeSignatureValue(sig.Signature)
+	if err != nil {
+		return err
+	}
+	if key.Certificate != nil {
+		intermediates := x509.NewCertPool()
+		for _, cert := range chain {
+			intermediates.AddCert(cert)
+		}
+		if _, err := key.Certificate.Verify(x509.VerifyOptions{
+			Roots:         v.Roots,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		}); err != nil {
+			return fmt.Errorf("signing certificate %q is not trusted: %w", key.Certificate.Subject, err)
+		}
+	}
+
+	switch pub := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		switch strings.ToUpper(sig.Signature.Algorithm) {
+		case SignatureAlgorithmRSAPSS:
+			err = rsa.VerifyPSS(pub, hash, sum, value, nil)
+		case SignatureAlgorithmRSAPKCS1v15, "":
+			err = rsa.VerifyPKCS1v15(pub, hash, sum, value)
+		default:
+			return fmt.Errorf("signature algorithm %q does not match the RSA key", sig.Signature.Algorithm)
+		}
+	case *ecdsa.PublicKey:
+		if algorithm := strings.ToUpper(sig.Signature.Algorithm); algorithm != "" && !strings.HasPrefix(algorithm, SignatureAlgorithmECDSA) {
+			return fmt.Errorf("signature algorithm %q does not match the ECDSA key", sig.Signature.Algorithm)
+		}
+		if !ecdsa.VerifyASN1(pub, sum, value) {
+			err = errors.New("invalid ECDSA signature")
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("signature does not verify with the key: %w", err)
+	}
+	return nil
+}
+
+// DescriptorDigest normalises a component descriptor with the given algorithm and returns the hex
+// digest of the result.
+func DescriptorDigest(descriptor *runtime.Descriptor, normalisationAlgorithm string, hash crypto.Hash) (string, error) {
+	normalised, err := normalisation.Normalise(descriptor, normalisationAlgorithm)
+	if err != nil {
+		return "", fmt.Errorf("failed to normalise descriptor with %q: %w", normalisationAlgorithm, err)
+	}
+	h := hash.New()
+	h.Write(normalised)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// signatureHash maps the hash algorithm of a digest, e.g. "SHA-256", to its implementation.
+func signatureHash(algorithm string) (crypto.Hash, error) {
+	switch strings.ToUpper(strings.ReplaceAll(algorithm, "-", "")) {
+	case "SHA256":
+		return crypto.SHA256, nil
+	case "SHA512":
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("unsupported digest hash algorithm %q", algorithm)
+	}
+}
+
+// decodeSignatureValue decodes a hex encoded signature, or a PEM encoded one followed by the
+// certificate chain of the signer.
+func decodeSignatureValue(info runtime.SignatureInfo) ([]byte, []*x509.Certificate, error) {
+	if !strings.HasPrefix(strings.TrimSpace(info.Value), "-----BEGIN") {
+		value, err := hex.DecodeString(info.Value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("signature value is not hex encoded: %w", err)
+		}
+		return value, nil, nil
+	}
+
+	var value []byte
+	var chain []*x509.Certificate
+	rest := []byte(info.Value)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		switch block.Type {
+		case pemSignatureBlock:
+			value = block.Bytes
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid certificate in signature: %w", err)
+			}
+			chain = append(chain, cert)
+		}
+	}
+	if value == nil {
+		return nil, nil, fmt.Errorf("signature value holds no %s block", pemSignatureBlock)
+	}
+	return value, chain, nil
+}
+
+// findSignature returns the signature with the given name.
+func findSignature(descriptor *runtime.Descriptor, name string) *runtime.Signature {
+	for i := range descriptor.Signatures {
+		if descriptor.Signatures[i].Name == name {
+			return &descriptor.Signatures[i]
+		}
+	}
+	return nil
+}
+
+// verifyComponentGraph verifies the signatures of every component version reachable from the
+// root before anything is scanned, and that referenced component versions match the digests
+// their parents recorded for them. All failures are reported together.
+func (c *CLIConverter) verifyComponentGraph(repo oci.ComponentVersionRepository, componentName, componentVersion string) error {
+	type reference struct {
+		Name, Version string
+		Parent        string
+		Digest        runtime.Digest
+	}
+	var report VerificationReport
+	fail := func(name, version, signature, reason string) {
+		report.Failures = append(report.Failures, VerificationFailure{Component: name, Version: version, Signature: signature, Reason: reason})
+	}
+
+	ctx := context.Background()
+	visited := make(map[string]*runtime.Descriptor)
+	queue := []reference{{Name: componentName, Version: componentVersion}}
+	for len(queue) > 0 {
+		curr := queue[0]
+		queue = queue[1:]
+		currID := fmt.Sprintf("%s:%s", curr.Name, curr.Version)
+
+		descriptor, seen := visited[currID]
+		if !seen {
+			var err error
+			descriptor, err = repo.GetComponentVersion(ctx, curr.Name, curr.Version)
+			visited[currID] = descriptor
+			if err != nil {
+				fail(curr.Name, curr.Version, "", fmt.Sprintf("could not get component version: %v", err))
+				continue
+			}
+		}
+		if descriptor == nil {
+			continue
+		}
+
+		// The parent's signature covers the digest it recorded for this component version
+		if d := curr.Digest; d.Value != "" {
+			if hash, err := signatureHash(d.HashAlgorithm); err != nil {
+				fail(curr.Name, curr.Version, "", fmt.Sprintf("reference from %s: %v", curr.Parent, err))
+			} else if digest, err := DescriptorDigest(descriptor, d.NormalisationAlgorithm, hash); err != nil {
+				fail(curr.Name, curr.Version, "", fmt.Sprintf("reference from %s: %v", curr.Parent, err))
+			} else if !strings.EqualFold(digest, d.Value) {
+				fail(curr.Name, curr.Version, "", fmt.Sprintf("digest mismatch with reference from %s: recorded %s, computed %s", curr.Parent, d.Value, digest))
+			}
+		}
+		if seen {
+			continue
+		}
+
+		failures := c.SignatureVerifier.VerifyDescriptor(descriptor)
+		report.Failures = append(report.Failures, failures...)
+		if len(failures) == 0 {
+			report.Verified = append(report.Verified, currID)
+			log.Printf("Verified signatures of %s", currID)
+		}
+
+		for _, ref := range descriptor.Component.References {
+			queue = append(queue, reference{Name: ref.Component, Version: ref.Version, Parent: currID, Digest: ref.Digest})
+		}
+	}
+
+	if len(report.Failures) > 0 {
+		failed := make(map[string]bool)
+		for _, f := range report.Failures {
+			failed[f.Component+":"+f.Version] = true
+		}
+		return fmt.Errorf("%w for %d of %d component versions:\n%s",
+			ErrSignatureVerification, len(failed), len(visited), report)
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+/*
+ * SPDX-FileCopyrightText: 2025 Olison Sturm
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	v4alpha1 "ocm.software/open-component-model/bindings/go/descriptor/normalisation/json/v4alpha1"
+	"ocm.software/open-component-model/bindings/go/descriptor/runtime"
+	"ocm.software/open-component-model/bindings/go/oci"
+)
+
+// descriptorRepo serves component versions from memory.
+type descriptorRepo struct {
+	oci.ComponentVersionRepository
+	descriptors map[string]*runtime.Descriptor
+}
+
+func (r descriptorRepo) GetComponentVersion(_ context.Context, name, version string) (*runtime.Descriptor, error) {
+	if d, ok := r.descriptors[name+":"+version]; ok {
+		return d, nil
+	}
+	return nil, errors.New("component version not found")
+}
+
+// testDescriptor returns a minimal descriptor of name in version 1.0.0.
+func testDescriptor(name string) *runtime.Descriptor {
+	d := &runtime.Descriptor{}
+	d.Meta.Version = "v2"
+	d.Component.Name = name
+	d.Component.Version = "1.0.0"
+	d.Component.Provider.Name = "acme.org"
+	return d
+}
+
+// signDescriptor signs the v4alpha1 digest of a descriptor and stores the signature under name,
+// replacing an earlier one of the same name.
+func signDescriptor(t *testing.T, d *runtime.Descriptor, name string, signer crypto.Signer, algorithm string) {
+	t.Helper()
+	digest, err := DescriptorDigest(d, v4alpha1.Algorithm, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, _ := hex.DecodeString(digest)
+	var opts crypto.SignerOpts = crypto.SHA256
+	if algorithm == SignatureAlgorithmRSAPSS {
+		opts = &rsa.PSSOptions{Hash: crypto.SHA256}
+	}
+	value, err := signer.Sign(rand.Reader, sum, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := runtime.Signature{
+		Name:      name,
+		Digest:    runtime.Digest{HashAlgorithm: "SHA-256", NormalisationAlgorithm: v4alpha1.Algorithm, Value: digest},
+		Signature: runtime.SignatureInfo{Algorithm: algorithm, Value: hex.EncodeToString(value)},
+	}
+	if existing := findSignature(d, name); existing != nil {
+		*existing = sig
+		return
+	}
+	d.Signatures = append(d.Signatures, sig)
+}
+
+// writePEMFile writes one PEM block to a file in dir and returns its path.
+func writePEMFile(t *testing.T, dir, file, blockType string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, file)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writePublicKey writes the public key of signer as PEM file and returns its path.
+func writePublicKey(t *testing.T, dir, file string, signer crypto.Signer) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return writePEMFile(t, dir, file, "PUBLIC KEY", der)
+}
+
+// testCA creates a self-signed CA and returns its certificate and key.
+func testCA(t *testing.T, name string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestVerifyDescriptor(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPath := writePublicKey(t, dir, "rsa.pem", rsaKey)
+	ecPath := writePublicKey(t, dir, "ec.pem", ecKey)
+	otherPath := writePublicKey(t, dir, "other.pem", otherKey)
+
+	tests := []struct {
+		name    string
+		signer  crypto.Signer
+		alg     string
+		key     string
+		modify  func(d *runtime.Descriptor)
+		wantErr string
+	}{
+		{name: "RSA PKCS1v15", signer: rsaKey, alg: SignatureAlgorithmRSAPKCS1v15, key: "release=" + rsaPath},
+		{name: "RSA-PSS", signer: rsaKey, alg: SignatureAlgorithmRSAPSS, key: "release=" + rsaPath},
+		{name: "ECDSA", signer: ecKey, alg: SignatureAlgorithmECDSA, key: "release=" + ecPath},
+		{
+			name: "modified descriptor", signer: rsaKey, alg: SignatureAlgorithmRSAPKCS1v15, key: "release=" + rsaPath,
+			modify:  func(d *runtime.Descriptor) { d.Component.Provider.Name = "evil.org" },
+			wantErr: "descriptor digest mismatch",
+		},
+		{name: "wrong key", signer: rsaKey, alg: SignatureAlgorithmRSAPKCS1v15, key: "release=" + otherPath, wantErr: "signature does not verify with the key"},
+		{name: "missing signature", signer: rsaKey, alg: SignatureAlgorithmRSAPKCS1v15, key: "nightly=" + rsaPath, wantErr: "signature not found"},
+		{
+			name: "unsupported hash", signer: rsaKey, alg: SignatureAlgorithmRSAPKCS1v15, key: "release=" + rsaPath,
+			modify:  func(d *runtime.Descriptor) { d.Signatures[0].Digest.HashAlgorithm = "MD5" },
+			wantErr: "unsupported digest hash algorithm",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			descriptor := testDescriptor("acme.org/app")
+			signDescriptor(t, descriptor, "release", tt.signer, tt.alg)
+			if tt.modify != nil {
+				tt.modify(descriptor)
+			}
+			verifier, err := NewSignatureVerifier([]string{tt.key}, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			failures := verifier.VerifyDescriptor(descriptor)
+			if tt.wantErr == "" {
+				if len(failures) != 0 {
+					t.Errorf("failures = %+v, want none", failures)
+				}
+				return
+			}
+			if len(failures) != 1 || !strings.Contains(failures[0].Reason, tt.wantErr) {
+				t.Errorf("failures = %+v, want %q", failures, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyDescriptorWithCertificate(t *testing.T) {
+	dir := t.TempDir()
+	signerKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, caKey := testCA(t, "ACME CA")
+	other, _ := testCA(t, "Other CA")
+	leaf := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "release signer"},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leaf, ca, &signerKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath := writePEMFile(t, dir, "signer.pem", "CERTIFICATE", leafDER)
+	caPath := writePEMFile(t, dir, "ca.pem", "CERTIFICATE", ca.Raw)
+	otherPath := writePEMFile(t, dir, "other-ca.pem", "CERTIFICATE", other.Raw)
+
+	descriptor := testDescriptor("acme.org/app")
+	signDescriptor(t, descriptor, "release", signerKey, SignatureAlgorithmRSAPKCS1v15)
+
+	if _, err := NewSignatureVerifier([]string{"release=" + certPath}, ""); err == nil {
+		t.Errorf("certificate accepted without a CA bundle")
+	}
+
+	trusted, err := NewSignatureVerifier([]string{"release=" + certPath}, caPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failures := trusted.VerifyDescriptor(descriptor); len(failures) != 0 {
+		t.Errorf("failures = %+v, want none for a certificate issued by the CA bundle", failures)
+	}
+
+	untrusted, err := NewSignatureVerifier([]string{"release=" + certPath}, otherPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failures := untrusted.VerifyDescriptor(descriptor); len(failures) != 1 || !strings.Contains(failures[0].Reason, "is not trusted") {
+		t.Errorf("failures = %+v, want the certificate rejected", failures)
+	}
+}
+
+func TestVerifyComponentGraphReportsReferenceDigestMismatch(t *testing.T) {
+	dir := t.TempDir()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := NewSignatureVerifier([]string{"release=" + writePublicKey(t, dir, "ec.pem", key)}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	child := testDescriptor("acme.org/child")
+	signDescriptor(t, child, "release", key, SignatureAlgorithmECDSA)
+	digest, err := DescriptorDigest(child, v4alpha1.Algorithm, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := testDescriptor("acme.org/root")
+	ref := runtime.Reference{Component: "acme.org/child", Digest: runtime.Digest{HashAlgorithm: "SHA-256", NormalisationAlgorithm: v4alpha1.Algorithm, Value: digest}}
+	ref.Name = "child"
+	ref.Version = "1.0.0"
+	root.Component.References = []runtime.Reference{ref}
+	signDescriptor(t, root, "release", key, SignatureAlgorithmECDSA)
+
+	c := &CLIConverter{SignatureVerifier: verifier}
+	repo := descriptorRepo{descriptors: map[string]*runtime.Descriptor{"acme.org/root:1.0.0": root, "acme.org/child:1.0.0": child}}
+	if err := c.verifyComponentGraph(repo, "acme.org/root", "1.0.0"); err != nil {
+		t.Fatalf("verifyComponentGraph: %v", err)
+	}
+
+	// Replace the child by a validly signed, different one
+	child.Component.Provider.Name = "evil.org"
+	signDescriptor(t, child, "release", key, SignatureAlgorithmECDSA)
+	err = c.verifyComponentGraph(repo, "acme.org/root", "1.0.0")
+	if !errors.Is(err, ErrSignatureVerification) {
+		t.Fatalf("error = %v, want %v", err, ErrSignatureVerification)
+	}
+	if !strings.Contains(err.Error(), "acme.org/child:1.0.0: digest mismatch with reference from acme.org/root:1.0.0") || !strings.Contains(err.Error(), "1 of 2") {
+		t.Errorf("error = %v, want the reference digest mismatch of the child only", err)
+	}
+}